@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os/signal"
+	"syscall"
 
 	"github.com/ezhdanovskiy/companies/internal/application"
 )
@@ -12,7 +15,10 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if err := app.Run(); err != nil {
-		log.Fatal(err)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := app.Run(ctx); err != nil {
+		log.Fatal(err) //nolint:gocritic
 	}
 }
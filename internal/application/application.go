@@ -2,15 +2,28 @@
 package application
 
 import (
+	"context"
+	"crypto/tls"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/commands"
+	"github.com/ezhdanovskiy/companies/internal/health"
 	"github.com/ezhdanovskiy/companies/internal/kafka"
+	"github.com/ezhdanovskiy/companies/internal/metrics"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/tlsconfig"
+	"github.com/ezhdanovskiy/companies/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
 	"github.com/uptrace/bun/extra/bundebug"
+	"github.com/uptrace/bun/extra/bunotel"
 	"go.uber.org/zap"
 
 	"github.com/ezhdanovskiy/companies/internal/config"
@@ -25,7 +38,14 @@ type Application struct {
 	cfg *config.Config
 	svc *service.Service
 
+	db         *bun.DB
+	producer   *kafka.AsyncProducer
+	health     *health.Checker
 	httpServer *http.Server
+	cancel     context.CancelFunc
+	workers    sync.WaitGroup
+
+	shutdownTracing func(ctx context.Context) error
 }
 
 // NewApplication creates and connects instances of all components required to run Application.
@@ -47,9 +67,21 @@ func NewApplication() (*Application, error) {
 	}, nil
 }
 
-// Run runs configured components.
-func (a *Application) Run() error {
+// Run runs configured components until ctx is canceled or the HTTP server fails, then stops them.
+func (a *Application) Run(ctx context.Context) error {
 	a.log.Info("Run application")
+	defer a.Stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, &tracing.Config{
+		Exporter:     a.cfg.Tracing.Exporter,
+		OTLPEndpoint: a.cfg.Tracing.OTLPEndpoint,
+		ServiceName:  a.cfg.Tracing.ServiceName,
+		SampleRatio:  a.cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return fmt.Errorf("setup tracing: %w", err)
+	}
+	a.shutdownTracing = shutdownTracing
 
 	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
 		a.cfg.DB.User, a.cfg.DB.Password, a.cfg.DB.Host, a.cfg.DB.Port, a.cfg.DB.DBName)
@@ -58,6 +90,10 @@ func (a *Application) Run() error {
 
 	// Print all queries to stdout.
 	db.AddQueryHook(bundebug.NewQueryHook(bundebug.WithVerbose(true)))
+	db.AddQueryHook(&metrics.QueryHook{})
+	db.AddQueryHook(bunotel.NewQueryHook(bunotel.WithDBName(a.cfg.DB.DBName)))
+
+	a.db = db
 
 	if err := db.Ping(); err != nil {
 		return fmt.Errorf("db pinf: %w", err)
@@ -72,33 +108,298 @@ func (a *Application) Run() error {
 		return fmt.Errorf("new repo: %w", err)
 	}
 
-	producer := kafka.NewAsyncProducer(&kafka.ProducerConfig{
+	// Messages the asynchronous producer fails to deliver go back to the outbox. The relay is the only publisher,
+	// so it is set before anything is published.
+	var relay *service.OutboxRelay
+	producer, err := kafka.NewAsyncProducer(a.log, &kafka.ProducerConfig{
 		Brokers:      []string{a.cfg.Kafka.Addr},
 		Topic:        a.cfg.Kafka.Topic,
 		BatchSize:    a.cfg.Kafka.BatchSize,
 		BatchTimeout: a.cfg.Kafka.BatchTimeout,
+		Delivery:     a.cfg.Kafka.Delivery,
+		MaxAttempts:  a.cfg.Kafka.MaxAttempts,
+		Compression:  a.cfg.Kafka.Compression,
+		OnDeliveryFailure: func(messages []*kafka.Message, err error) {
+			relay.Requeue(messages, err)
+		},
 	})
+	if err != nil {
+		return fmt.Errorf("new producer: %w", err)
+	}
+	a.producer = producer
+	if !producer.Synchronous() {
+		a.log.Warnf("Kafka delivery mode %q is asynchronous, undelivered events are requeued out of order", a.cfg.Kafka.Delivery)
+	}
 
-	a.svc = service.NewService(a.log, repo, producer)
+	if err := prometheus.Register(kafka.NewProducerCollector(producer)); err != nil {
+		return fmt.Errorf("register producer collector: %w", err)
+	}
 
-	auth.SetJWTKey(a.cfg.JWTKey)
-	a.httpServer = http.NewServer(a.log, a.cfg.HTTPPort, a.svc)
+	a.svc, err = service.NewService(a.log, repo, &service.EventsConfig{
+		Format: a.cfg.Kafka.EventFormat,
+		Source: a.cfg.Kafka.EventSource,
+	}, &service.AuthConfig{
+		RefreshTokenTTL: a.cfg.RefreshTokenTTL,
+	}, &service.IdempotencyConfig{
+		KeyTTL:      a.cfg.IdempotencyKeyTTL,
+		LockTimeout: a.cfg.IdempotencyLockTTL,
+	})
+	if err != nil {
+		return fmt.Errorf("new service: %w", err)
+	}
+
+	relay, err = service.NewOutboxRelay(a.log, repo, producer, &service.RelayConfig{
+		PollInterval: a.cfg.Outbox.PollInterval,
+		BatchSize:    a.cfg.Outbox.BatchSize,
+		BackoffMin:   a.cfg.Outbox.BackoffMin,
+		BackoffMax:   a.cfg.Outbox.BackoffMax,
+	})
+	if err != nil {
+		return fmt.Errorf("new outbox relay: %w", err)
+	}
+
+	var purger *service.Purger
+	if a.cfg.Purge.Retention > 0 {
+		purger, err = service.NewPurger(a.log, a.svc, &service.PurgeConfig{
+			Interval:  a.cfg.Purge.Interval,
+			Retention: a.cfg.Purge.Retention,
+			BatchSize: a.cfg.Purge.BatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("new purger: %w", err)
+		}
+	}
 
-	a.log.Infof("Run HTTP server on port %v", a.cfg.HTTPPort)
+	// Background workers get their own context to be stopped only after the HTTP server is drained.
+	workersCtx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
 
-	if err := a.httpServer.Run(); err != nil {
-		return fmt.Errorf("HTTP server run: %w", err)
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		relay.Run(workersCtx)
+	}()
+
+	if purger != nil {
+		a.log.Infof("Purge companies deleted more than %v ago", a.cfg.Purge.Retention)
+		a.workers.Add(1)
+		go func() {
+			defer a.workers.Done()
+			purger.Run(workersCtx)
+		}()
 	}
-	a.log.Info("HTTP server stopped")
 
-	a.log.Info("Application stopped")
+	var consumer *kafka.Consumer
+	if a.cfg.Kafka.CommandsTopic != "" {
+		consumer = kafka.NewConsumer(a.log, &kafka.ConsumerConfig{
+			Brokers:         []string{a.cfg.Kafka.Addr},
+			Topic:           a.cfg.Kafka.CommandsTopic,
+			GroupID:         a.cfg.Kafka.ConsumerGroup,
+			DeadLetterTopic: a.cfg.Kafka.DeadLetterTopic,
+			MaxRetries:      a.cfg.Kafka.ConsumerMaxRetries,
+			RetryBackoff:    a.cfg.Kafka.ConsumerRetryBackoff,
+		}, commands.NewHandler(a.log, a.svc).Handle)
+
+		a.log.Infof("Consume commands from topic %v", a.cfg.Kafka.CommandsTopic)
+		a.workers.Add(1)
+		go func() {
+			defer a.workers.Done()
+			consumer.Run(workersCtx)
+		}()
+	}
+
+	if err := a.createAdmin(ctx); err != nil {
+		return fmt.Errorf("create admin: %w", err)
+	}
+
+	a.health = health.NewChecker()
+	a.health.Add("db", a.cfg.HealthCheckTimeout, repo.Ping)
+	a.health.Add("migrations", a.cfg.HealthCheckTimeout, func(ctx context.Context) error {
+		version, dirty, err := repo.MigrationState(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("migration %d is dirty", version)
+		}
+		return nil
+	})
+	a.health.Add("kafka", a.cfg.HealthCheckTimeout, producer.Ping)
+	if consumer != nil {
+		a.health.Add("commands", a.cfg.HealthCheckTimeout, consumer.Ping)
+	}
+
+	if err := a.setupTokenKeys(ctx, workersCtx); err != nil {
+		return fmt.Errorf("setup token keys: %w", err)
+	}
+	tlsConfig, err := a.setupTLS(workersCtx)
+	if err != nil {
+		return fmt.Errorf("setup TLS: %w", err)
+	}
+	a.httpServer, err = http.NewServer(a.log, a.cfg.HTTPPort, a.cfg.Tracing.ServiceName, a.svc, a.health, tlsConfig,
+		a.cfg.HTTPTrustedProxies)
+	if err != nil {
+		return fmt.Errorf("create HTTP server: %w", err)
+	}
+
+	a.log.With("tls", tlsConfig != nil).Infof("Run HTTP server on port %v", a.cfg.HTTPPort)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- a.httpServer.Run()
+	}()
+
+	select {
+	case <-ctx.Done():
+		a.log.Info("Shutdown requested")
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("HTTP server run: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// setupTokenKeys configures signing of access tokens. Asymmetric keys are refreshed until workersCtx is canceled.
+func (a *Application) setupTokenKeys(ctx, workersCtx context.Context) error {
+	if a.cfg.JWT.Algorithm == auth.AlgorithmHS256 {
+		if a.cfg.JWTKey == auth.DefaultJWTKey {
+			a.log.Warn("Access tokens are signed with the default JWT key, set jwt_key")
+		}
+		auth.SetJWTKey(a.cfg.JWTKey)
+		return nil
+	}
+
+	keys, err := auth.NewKeySet(ctx, a.log, &auth.KeysConfig{
+		Algorithm:            a.cfg.JWT.Algorithm,
+		SigningKeyFile:       a.cfg.JWT.SigningKeyFile,
+		VerificationKeyFiles: a.cfg.JWT.VerificationKeyFiles,
+		JWKSURL:              a.cfg.JWT.JWKSURL,
+		GracePeriod:          a.cfg.JWT.KeyGracePeriod,
+		RefreshInterval:      a.cfg.JWT.KeysRefreshInterval,
+	})
+	if err != nil {
+		return err
+	}
+	auth.SetKeySet(keys)
+
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		keys.Run(workersCtx)
+	}()
+
 	return nil
 }
 
-// Stop terminates configured components.
+// setupTLS returns the TLS config of the HTTP server or nil if TLS is disabled.
+// Certificates are reloaded on change until workersCtx is canceled.
+func (a *Application) setupTLS(workersCtx context.Context) (*tls.Config, error) {
+	if a.cfg.TLS.CertFile == "" {
+		return nil, nil
+	}
+
+	reloader, err := tlsconfig.NewReloader(a.log, &tlsconfig.Config{
+		CertFile:       a.cfg.TLS.CertFile,
+		KeyFile:        a.cfg.TLS.KeyFile,
+		MinVersion:     a.cfg.TLS.MinVersion,
+		CipherSuites:   a.cfg.TLS.CipherSuites,
+		ClientCAFile:   a.cfg.TLS.ClientCAFile,
+		ClientAuth:     a.cfg.TLS.ClientAuth,
+		ReloadInterval: a.cfg.TLS.ReloadInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		reloader.Run(workersCtx)
+	}()
+
+	return reloader.TLSConfig(), nil
+}
+
+// createAdmin creates the configured administrator unless a user with such name exists.
+func (a *Application) createAdmin(ctx context.Context) error {
+	if a.cfg.AdminUsername == "" {
+		return nil
+	}
+	if a.cfg.AdminPassword == "" {
+		return errors.New("admin_password is required with admin_username")
+	}
+
+	_, err := a.svc.CreateUser(ctx, &models.NewUser{
+		Username: a.cfg.AdminUsername,
+		Email:    a.cfg.AdminEmail,
+		Password: a.cfg.AdminPassword,
+		Roles:    []string{auth.RoleAdmin},
+	})
+	if errors.Is(err, models.ErrUserAlreadyExists) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	a.log.With("username", a.cfg.AdminUsername).Info("Administrator created")
+	return nil
+}
+
+// Stop terminates configured components in dependency order: /readyz starts failing and the HTTP server
+// keeps serving for HTTPShutdownDelay so the orchestrator stops routing traffic to it, then the server
+// stops accepting connections and drains in-flight requests, background workers finish the messages they process,
+// the producer flushes buffered messages and the DB is closed last.
+// It is called by Run on return.
 func (a *Application) Stop() {
+	if a.health != nil {
+		a.health.SetShuttingDown()
+	}
+
 	if a.httpServer != nil {
+		if a.cfg.HTTPShutdownDelay > 0 {
+			a.log.Infof("Waiting %v for readiness to propagate", a.cfg.HTTPShutdownDelay)
+			time.Sleep(a.cfg.HTTPShutdownDelay)
+		}
+
 		a.log.Info("Stopping HTTP server")
-		a.httpServer.Shutdown()
+		a.httpServer.Shutdown(a.cfg.HTTPShutdownTimeout)
+		a.httpServer = nil
+		a.log.Info("HTTP server stopped")
 	}
+
+	if a.cancel != nil {
+		a.log.Info("Stopping background workers")
+		a.cancel()
+		a.workers.Wait()
+		a.cancel = nil
+	}
+
+	if a.producer != nil {
+		a.log.Info("Closing Kafka producer")
+		if err := a.producer.Close(); err != nil {
+			a.log.With("error", err).Error("Failed to close Kafka producer")
+		}
+		a.producer = nil
+	}
+
+	if a.db != nil {
+		a.log.Info("Closing DB")
+		if err := a.db.Close(); err != nil {
+			a.log.With("error", err).Error("Failed to close DB")
+		}
+		a.db = nil
+	}
+
+	if a.shutdownTracing != nil {
+		a.log.Info("Flushing traces")
+		if err := a.shutdownTracing(context.Background()); err != nil {
+			a.log.With("error", err).Error("Failed to flush traces")
+		}
+		a.shutdownTracing = nil
+	}
+
+	a.log.Info("Application stopped")
 }
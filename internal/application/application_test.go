@@ -0,0 +1,19 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/ezhdanovskiy/companies/internal/config"
+	"go.uber.org/zap"
+)
+
+func TestApplication_Stop_NothingStarted(t *testing.T) {
+	a := &Application{
+		log: zap.NewNop().Sugar(),
+		cfg: &config.Config{},
+	}
+
+	// Stop must tolerate components that were not created and repeated calls.
+	a.Stop()
+	a.Stop()
+}
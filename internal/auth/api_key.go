@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+const (
+	apiKeySize = 32
+
+	// APIKeyPrefix marks API keys, so leaked keys are easy to find by secret scanners.
+	APIKeyPrefix = "ck_"
+
+	// apiKeyDisplayLength is the number of the first characters of a key stored to identify it.
+	apiKeyDisplayLength = len(APIKeyPrefix) + 8
+)
+
+// NewAPIKey generates an API key, its hash to be stored instead of the key and the prefix identifying it.
+func NewAPIKey() (key, hash, prefix string, err error) {
+	b := make([]byte, apiKeySize)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, HashAPIKey(key), key[:apiKeyDisplayLength], nil
+}
+
+// HashAPIKey returns the hex encoded SHA-256 hash of the API key.
+func HashAPIKey(key string) string {
+	return HashRefreshToken(key)
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyThumbprint(t *testing.T) {
+	// The example of RFC 7638, section 3.1.
+	jwk := JSONWebKey{
+		KeyType: "RSA",
+		N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMs" +
+			"tn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1" +
+			"n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E: "AQAB",
+	}
+	public, err := jwk.PublicKey()
+	require.NoError(t, err)
+
+	kid, err := keyThumbprint(public)
+	require.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", kid)
+}
+
+func TestJSONWebKey_PublicKey_Unsupported(t *testing.T) {
+	tests := []JSONWebKey{
+		{KeyType: "oct"},
+		{KeyType: "EC", Curve: "P-384"},
+		{KeyType: "OKP", Curve: "X25519"},
+		{KeyType: "EC", Curve: "P-256", X: "AQ", Y: "AQ"},
+	}
+	for _, jwk := range tests {
+		_, err := jwk.PublicKey()
+		assert.Error(t, err, jwk)
+	}
+}
@@ -4,23 +4,51 @@ import (
 	"errors"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
-var jwtKey = "supersecretkey"
+// DefaultJWTKey is the development HMAC secret, it must be overridden in production.
+const DefaultJWTKey = "supersecretkey"
 
+var jwtKey = DefaultJWTKey
+
+// keys signs and verifies tokens with asymmetric algorithms, HS256 with jwtKey is used if nil.
+var keys *KeySet
+
+// SetJWTKey switches tokens to HS256 with the key.
 func SetJWTKey(key string) {
 	jwtKey = key
+	keys = nil
 }
 
+// SetKeySet switches tokens to the asymmetric algorithm of the key set.
+func SetKeySet(ks *KeySet) {
+	keys = ks
+}
+
+// JWKS returns the public keys of issued tokens, it is empty for HS256.
+func JWKS() JSONWebKeySet {
+	if keys == nil {
+		return JSONWebKeySet{Keys: []JSONWebKey{}}
+	}
+	return keys.JWKS()
+}
+
+// AccessTokenTTL is the lifetime of issued access tokens.
+const AccessTokenTTL = time.Hour
+
 type JWTClaim struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
+	Username string   `json:"username"`
+	Email    string   `json:"email"`
+	Roles    []string `json:"roles,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
 	jwt.StandardClaims
 }
 
 func GenerateJWT(email, username string) (tokenString string, err error) {
-	expirationTime := time.Now().Add(1 * time.Hour)
+	expirationTime := time.Now().Add(AccessTokenTTL)
 	claims := &JWTClaim{
 		Email:    email,
 		Username: username,
@@ -28,19 +56,56 @@ func GenerateJWT(email, username string) (tokenString string, err error) {
 			ExpiresAt: expirationTime.Unix(),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err = token.SignedString([]byte(jwtKey))
-	return
+	return signToken(claims)
 }
 
-func ValidateToken(signedToken string) (err error) {
-	token, err := jwt.ParseWithClaims(
-		signedToken,
-		&JWTClaim{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtKey), nil
+// GenerateAccessToken issues a token for the user valid for AccessTokenTTL.
+func GenerateAccessToken(user *models.User) (*models.AccessToken, error) {
+	expirationTime := time.Now().Add(AccessTokenTTL)
+	claims := &JWTClaim{
+		Email:    user.Email,
+		Username: user.Username,
+		Roles:    user.Roles,
+		Scopes:   ScopesForRoles(user.Roles),
+		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.New().String(),
+			Subject:   user.ID,
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: expirationTime.Unix(),
 		},
-	)
+	}
+	tokenString, err := signToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AccessToken{
+		ID:        claims.Id,
+		Token:     tokenString,
+		ExpiresAt: expirationTime,
+	}, nil
+}
+
+// Principal returns the actor authenticated by the token.
+func (c *JWTClaim) Principal() *models.Principal {
+	return &models.Principal{
+		Type:   models.PrincipalUser,
+		ID:     c.Subject,
+		Name:   c.Username,
+		Email:  c.Email,
+		Roles:  c.Roles,
+		Scopes: c.Scopes,
+	}
+}
+
+func ValidateToken(signedToken string) (err error) {
+	_, err = ParseToken(signedToken)
+	return
+}
+
+// ParseToken validates the token and returns its claims.
+func ParseToken(signedToken string) (claims *JWTClaim, err error) {
+	token, err := newParser().ParseWithClaims(signedToken, &JWTClaim{}, keyFunc)
 
 	if err != nil {
 		return
@@ -57,5 +122,28 @@ func ValidateToken(signedToken string) (err error) {
 		return
 	}
 
-	return
+	return claims, nil
+}
+
+func signToken(claims jwt.Claims) (string, error) {
+	if keys != nil {
+		return keys.sign(claims)
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(jwtKey))
+}
+
+// newParser accepts only the algorithms of the configured keys, so e.g. an HS256 token
+// signed with a public key or an unsigned token is rejected before the key lookup.
+func newParser() *jwt.Parser {
+	if keys != nil {
+		return jwt.NewParser(jwt.WithValidMethods(keys.validMethods()))
+	}
+	return jwt.NewParser(jwt.WithValidMethods([]string{AlgorithmHS256}))
+}
+
+func keyFunc(token *jwt.Token) (interface{}, error) {
+	if keys != nil {
+		return keys.keyFunc(token)
+	}
+	return []byte(jwtKey), nil
 }
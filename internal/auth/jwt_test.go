@@ -5,7 +5,8 @@ import (
 	"testing"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -116,3 +117,48 @@ func TestValidateToken_EmptyFields(t *testing.T) {
 	err = ValidateToken(tokenString)
 	assert.NoError(t, err)
 }
+
+func TestGenerateAccessToken(t *testing.T) {
+	user := &models.User{ID: "user-id", Username: "admin", Email: "admin@example.com", Roles: []string{RoleEditor}}
+
+	token, err := GenerateAccessToken(user)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(AccessTokenTTL), token.ExpiresAt, time.Minute)
+
+	claims, err := ParseToken(token.Token)
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, claims.Id)
+	assert.NotEmpty(t, claims.Id)
+	assert.Equal(t, "user-id", claims.Subject)
+	assert.Equal(t, "admin", claims.Username)
+	assert.Equal(t, "admin@example.com", claims.Email)
+	assert.Equal(t, []string{RoleEditor}, claims.Roles)
+	assert.Equal(t, []string{ScopeCompaniesRead, ScopeCompaniesWrite}, claims.Scopes)
+
+	principal := claims.Principal()
+	assert.Equal(t, "user-id", principal.ID)
+	assert.True(t, principal.HasScope(ScopeCompaniesWrite))
+	assert.False(t, principal.HasScope(ScopeCompaniesDelete))
+}
+
+func TestNewRefreshToken(t *testing.T) {
+	token1, hash1, err := NewRefreshToken()
+	require.NoError(t, err)
+	token2, hash2, err := NewRefreshToken()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, token1, token2)
+	assert.NotEqual(t, hash1, hash2)
+	assert.Len(t, hash1, 64)
+	assert.Equal(t, hash1, HashRefreshToken(token1))
+}
+
+func TestNewAPIKey(t *testing.T) {
+	key, hash, prefix, err := NewAPIKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(key, APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(key, prefix))
+	assert.Len(t, prefix, apiKeyDisplayLength)
+	assert.Equal(t, hash, HashAPIKey(key))
+}
@@ -0,0 +1,346 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
+)
+
+// jwksFetchTimeout limits fetching of the JWKS URL.
+const jwksFetchTimeout = 10 * time.Second
+
+var errUnknownKey = errors.New("unknown signing key")
+
+// KeysConfig configures the key set of asymmetric access tokens.
+type KeysConfig struct {
+	Algorithm            string        // RS256 | ES256 | EdDSA
+	SigningKeyFile       string        // PEM private key of issued tokens, issuing is disabled if empty
+	VerificationKeyFiles []string      // PEM public keys of previous or external signers
+	JWKSURL              string        // JWKS of external signers, skipped if empty
+	GracePeriod          time.Duration // keys removed from the sources keep verifying tokens for this time
+	RefreshInterval      time.Duration
+}
+
+// verificationKey is a public key of the set identified by the thumbprint or the JWKS kid.
+type verificationKey struct {
+	id        string
+	algorithm string
+	public    crypto.PublicKey
+	issued    bool      // the key signed tokens of the service
+	retiredAt time.Time // the key stopped signing tokens
+	removedAt time.Time // the key disappeared from the sources
+}
+
+// KeySet signs access tokens with the current key and verifies them with the keys selected by kid.
+// Keys are reloaded from the sources by Refresh. A key that is rotated out keeps verifying tokens
+// during the grace period, a retired signing key stays published in JWKS for the same time.
+type KeySet struct {
+	log    *zap.SugaredLogger
+	cfg    KeysConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu         sync.RWMutex
+	signer     crypto.Signer
+	signingKID string
+	keys       map[string]*verificationKey
+}
+
+// NewKeySet loads the keys from the configured sources.
+func NewKeySet(ctx context.Context, log *zap.SugaredLogger, cfg *KeysConfig) (*KeySet, error) {
+	switch cfg.Algorithm {
+	case AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA:
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", cfg.Algorithm)
+	}
+
+	ks := &KeySet{
+		log:    log,
+		cfg:    *cfg,
+		client: &http.Client{Timeout: jwksFetchTimeout},
+		now:    time.Now,
+		keys:   make(map[string]*verificationKey),
+	}
+	if err := ks.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// Run refreshes the keys every RefreshInterval until ctx is canceled.
+// Failed refreshes are logged, the loaded keys are kept.
+func (ks *KeySet) Run(ctx context.Context) {
+	if ks.cfg.RefreshInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(ks.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ks.Refresh(ctx); err != nil {
+				ks.log.With("error", err).Error("Failed to refresh signing keys")
+			}
+		}
+	}
+}
+
+// Refresh reloads the keys from the sources. The set isn't changed if any source fails.
+func (ks *KeySet) Refresh(ctx context.Context) error {
+	loaded := make(map[string]*verificationKey)
+
+	var signer crypto.Signer
+	var signingKID string
+	if ks.cfg.SigningKeyFile != "" {
+		key, err := ks.loadSigningKey()
+		if err != nil {
+			return fmt.Errorf("load signing key %s: %w", ks.cfg.SigningKeyFile, err)
+		}
+		signer, signingKID = key.signer, key.id
+		loaded[key.id] = &key.verificationKey
+	}
+
+	for _, path := range ks.cfg.VerificationKeyFiles {
+		key, err := loadVerificationKey(path)
+		if err != nil {
+			return fmt.Errorf("load verification key %s: %w", path, err)
+		}
+		if _, ok := loaded[key.id]; !ok {
+			loaded[key.id] = key
+		}
+	}
+
+	if ks.cfg.JWKSURL != "" {
+		fetched, err := ks.fetchJWKS(ctx)
+		if err != nil {
+			return fmt.Errorf("fetch JWKS %s: %w", ks.cfg.JWKSURL, err)
+		}
+		for _, key := range fetched {
+			if _, ok := loaded[key.id]; !ok {
+				loaded[key.id] = key
+			}
+		}
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	now := ks.now()
+	for id, old := range ks.keys {
+		if old.issued && id != signingKID && old.retiredAt.IsZero() {
+			old.retiredAt = now
+			ks.log.With("kid", id).Info("Signing key retired")
+		}
+
+		key, ok := loaded[id]
+		if !ok {
+			if old.removedAt.IsZero() {
+				old.removedAt = now
+				ks.log.With("kid", id).Info("Key removed, it is accepted during the grace period")
+			}
+			if now.Before(old.removedAt.Add(ks.cfg.GracePeriod)) {
+				loaded[id] = old
+			}
+			continue
+		}
+		if old.issued && !key.issued {
+			key.issued, key.retiredAt = true, old.retiredAt
+		}
+	}
+
+	if signingKID != ks.signingKID {
+		ks.log.With("kid", signingKID).Info("Signing key loaded")
+	}
+	ks.signer, ks.signingKID, ks.keys = signer, signingKID, loaded
+	return nil
+}
+
+type signingKey struct {
+	verificationKey
+	signer crypto.Signer
+}
+
+func (ks *KeySet) loadSigningKey() (*signingKey, error) {
+	data, err := os.ReadFile(ks.cfg.SigningKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := parsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newVerificationKey("", signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	if key.algorithm != ks.cfg.Algorithm {
+		return nil, fmt.Errorf("key of %s can't sign %s tokens", key.algorithm, ks.cfg.Algorithm)
+	}
+	key.issued = true
+
+	return &signingKey{verificationKey: *key, signer: signer}, nil
+}
+
+func loadVerificationKey(path string) (*verificationKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	public, err := parsePublicKeyPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	return newVerificationKey("", public)
+}
+
+// fetchJWKS loads the keys of the JWKS URL. Keys not intended for signatures or of unsupported types are skipped.
+func (ks *KeySet) fetchJWKS(ctx context.Context) ([]*verificationKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.cfg.JWKSURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var set JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	keys := make([]*verificationKey, 0, len(set.Keys))
+	for i := range set.Keys {
+		jwk := &set.Keys[i]
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		public, err := jwk.PublicKey()
+		if err != nil {
+			ks.log.With("kid", jwk.KeyID, "error", err).Warn("JWKS key skipped")
+			continue
+		}
+		key, err := newVerificationKey(jwk.KeyID, public)
+		if err != nil {
+			ks.log.With("kid", jwk.KeyID, "error", err).Warn("JWKS key skipped")
+			continue
+		}
+		if jwk.Algorithm != "" && jwk.Algorithm != key.algorithm {
+			ks.log.With("kid", jwk.KeyID, "alg", jwk.Algorithm).Warn("JWKS key of unsupported algorithm skipped")
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// newVerificationKey creates a key identified by kid or by the thumbprint if kid is empty.
+func newVerificationKey(kid string, public crypto.PublicKey) (*verificationKey, error) {
+	alg, err := algorithmForKey(public)
+	if err != nil {
+		return nil, err
+	}
+	if kid == "" {
+		kid, err = keyThumbprint(public)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &verificationKey{id: kid, algorithm: alg, public: public}, nil
+}
+
+// sign signs the claims with the current signing key and sets its kid in the header.
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if ks.signer == nil {
+		return "", errors.New("no signing key configured")
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(ks.cfg.Algorithm), claims)
+	token.Header["kid"] = ks.signingKID
+	return token.SignedString(ks.signer)
+}
+
+// validMethods returns the algorithms of the keys, tokens signed with other algorithms are rejected.
+// The result is never nil, so the parser rejects all tokens if the set is empty.
+func (ks *KeySet) validMethods() []string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	methods := []string{}
+	for _, key := range ks.keys {
+		if !seen[key.algorithm] {
+			seen[key.algorithm] = true
+			methods = append(methods, key.algorithm)
+		}
+	}
+	return methods
+}
+
+// keyFunc selects the verification key by the kid of the token header.
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no key ID")
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[kid]
+	if !ok || !key.removedAt.IsZero() && !ks.now().Before(key.removedAt.Add(ks.cfg.GracePeriod)) {
+		return nil, fmt.Errorf("%w %q", errUnknownKey, kid)
+	}
+	if alg := token.Method.Alg(); alg != key.algorithm {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", alg, kid)
+	}
+
+	return key.public, nil
+}
+
+// JWKS returns the public keys of issued tokens: the signing key and the keys retired during the grace period.
+func (ks *KeySet) JWKS() JSONWebKeySet {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := ks.now()
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range ks.keys {
+		if !key.issued || !key.retiredAt.IsZero() && !now.Before(key.retiredAt.Add(ks.cfg.GracePeriod)) {
+			continue
+		}
+		jwk, err := newJSONWebKey(key.id, key.algorithm, key.public)
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+
+	return set
+}
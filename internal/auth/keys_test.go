@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestKeySet_SignAndVerify(t *testing.T) {
+	tests := []struct {
+		alg    string
+		newKey func(t *testing.T) crypto.Signer
+	}{
+		{alg: AlgorithmRS256, newKey: newRSAKey},
+		{alg: AlgorithmES256, newKey: newECKey},
+		{alg: AlgorithmEdDSA, newKey: newEd25519Key},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			key := tt.newKey(t)
+			ks := newTestKeySet(t, &KeysConfig{Algorithm: tt.alg, SigningKeyFile: writePrivateKey(t, key)})
+
+			token, err := GenerateAccessToken(&models.User{ID: "user-id", Username: "user"})
+			require.NoError(t, err)
+
+			parsed, _, err := new(jwt.Parser).ParseUnverified(token.Token, &JWTClaim{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.alg, parsed.Method.Alg())
+			assert.Equal(t, thumbprint(t, key.Public()), parsed.Header["kid"])
+
+			claims, err := ParseToken(token.Token)
+			require.NoError(t, err)
+			assert.Equal(t, "user-id", claims.Subject)
+
+			jwks := ks.JWKS()
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, parsed.Header["kid"], jwks.Keys[0].KeyID)
+			assert.Equal(t, tt.alg, jwks.Keys[0].Algorithm)
+			public, err := jwks.Keys[0].PublicKey()
+			require.NoError(t, err)
+			assert.True(t, key.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(public))
+		})
+	}
+}
+
+func TestNewKeySet_UnsupportedAlgorithm(t *testing.T) {
+	_, err := NewKeySet(context.Background(), zap.NewNop().Sugar(), &KeysConfig{Algorithm: "none"})
+	assert.Error(t, err)
+}
+
+func TestNewKeySet_KeyOfOtherAlgorithm(t *testing.T) {
+	_, err := NewKeySet(context.Background(), zap.NewNop().Sugar(), &KeysConfig{
+		Algorithm:      AlgorithmRS256,
+		SigningKeyFile: writePrivateKey(t, newECKey(t)),
+	})
+	assert.Error(t, err)
+}
+
+func TestKeySet_RejectsUnexpectedTokens(t *testing.T) {
+	rsaKey, ecKey := newRSAKey(t), newECKey(t)
+	newTestKeySet(t, &KeysConfig{
+		Algorithm:            AlgorithmRS256,
+		SigningKeyFile:       writePrivateKey(t, rsaKey),
+		VerificationKeyFiles: []string{writePublicKey(t, ecKey.Public())},
+	})
+	rsaKID, ecKID := thumbprint(t, rsaKey.Public()), thumbprint(t, ecKey.Public())
+	claims := &JWTClaim{StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()}}
+
+	t.Run("valid", func(t *testing.T) {
+		_, err := ParseToken(signTestToken(t, jwt.SigningMethodES256, ecKID, claims, ecKey))
+		assert.NoError(t, err)
+	})
+	t.Run("HMAC with the public key", func(t *testing.T) {
+		der, err := x509.MarshalPKIXPublicKey(rsaKey.Public())
+		require.NoError(t, err)
+		publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+		_, err = ParseToken(signTestToken(t, jwt.SigningMethodHS256, rsaKID, claims, publicPEM))
+		assert.Error(t, err)
+	})
+	t.Run("none", func(t *testing.T) {
+		_, err := ParseToken(signTestToken(t, jwt.SigningMethodNone, rsaKID, claims, jwt.UnsafeAllowNoneSignatureType))
+		assert.Error(t, err)
+	})
+	t.Run("algorithm of another key", func(t *testing.T) {
+		_, err := ParseToken(signTestToken(t, jwt.SigningMethodES256, rsaKID, claims, ecKey))
+		assert.ErrorContains(t, err, "unexpected signing method")
+	})
+	t.Run("unknown kid", func(t *testing.T) {
+		_, err := ParseToken(signTestToken(t, jwt.SigningMethodRS256, "unknown", claims, rsaKey))
+		assert.ErrorIs(t, err, errUnknownKey)
+	})
+	t.Run("no kid", func(t *testing.T) {
+		_, err := ParseToken(signTestToken(t, jwt.SigningMethodRS256, "", claims, rsaKey))
+		assert.Error(t, err)
+	})
+}
+
+func TestParseToken_HMACRejectsAsymmetric(t *testing.T) {
+	claims := &JWTClaim{StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()}}
+
+	_, err := ParseToken(signTestToken(t, jwt.SigningMethodRS256, "", claims, newRSAKey(t)))
+	assert.Error(t, err)
+
+	_, err = ParseToken(signTestToken(t, jwt.SigningMethodNone, "", claims, jwt.UnsafeAllowNoneSignatureType))
+	assert.Error(t, err)
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey, newKey := newEd25519Key(t), newEd25519Key(t)
+	signingKeyFile := writePrivateKey(t, oldKey)
+	ks := newTestKeySet(t, &KeysConfig{
+		Algorithm:      AlgorithmEdDSA,
+		SigningKeyFile: signingKeyFile,
+		GracePeriod:    time.Hour,
+	})
+	now := time.Now()
+	ks.now = func() time.Time { return now }
+
+	oldToken, err := GenerateAccessToken(&models.User{ID: "user-id"})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(signingKeyFile, marshalPrivateKey(t, newKey), 0o600))
+	require.NoError(t, ks.Refresh(context.Background()))
+
+	newToken, err := GenerateAccessToken(&models.User{ID: "user-id"})
+	require.NoError(t, err)
+	_, err = ParseToken(newToken.Token)
+	assert.NoError(t, err)
+	_, err = ParseToken(oldToken.Token)
+	assert.NoError(t, err) // The old key is accepted during the grace period
+	assert.Len(t, ks.JWKS().Keys, 2)
+
+	now = now.Add(time.Hour)
+	_, err = ParseToken(oldToken.Token)
+	assert.ErrorIs(t, err, errUnknownKey)
+
+	require.NoError(t, ks.Refresh(context.Background()))
+	jwks := ks.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, thumbprint(t, newKey.Public()), jwks.Keys[0].KeyID)
+}
+
+func TestKeySet_JWKSURL(t *testing.T) {
+	external := newECKey(t)
+	jwk, err := newJSONWebKey("external-1", AlgorithmES256, external.Public())
+	require.NoError(t, err)
+	encryption, err := newJSONWebKey("external-enc", "", newRSAKey(t).Public())
+	require.NoError(t, err)
+	encryption.Use = "enc"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(JSONWebKeySet{Keys: []JSONWebKey{jwk, encryption}})
+	}))
+	defer server.Close()
+
+	ks := newTestKeySet(t, &KeysConfig{Algorithm: AlgorithmES256, JWKSURL: server.URL})
+	assert.Empty(t, ks.JWKS().Keys) // Keys of other issuers aren't published
+	assert.Equal(t, []string{AlgorithmES256}, ks.validMethods())
+
+	claims := &JWTClaim{
+		Username:       "external",
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+	}
+	parsed, err := ParseToken(signTestToken(t, jwt.SigningMethodES256, "external-1", claims, external))
+	require.NoError(t, err)
+	assert.Equal(t, "external", parsed.Username)
+
+	_, err = GenerateAccessToken(&models.User{ID: "user-id"})
+	assert.Error(t, err) // No signing key
+}
+
+func TestKeySet_JWKSURLUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := NewKeySet(context.Background(), zap.NewNop().Sugar(), &KeysConfig{
+		Algorithm: AlgorithmES256,
+		JWKSURL:   server.URL,
+	})
+	assert.Error(t, err)
+}
+
+// newTestKeySet loads the key set and installs it for the test.
+func newTestKeySet(t *testing.T, cfg *KeysConfig) *KeySet {
+	ks, err := NewKeySet(context.Background(), zap.NewNop().Sugar(), cfg)
+	require.NoError(t, err)
+
+	SetKeySet(ks)
+	t.Cleanup(func() { SetKeySet(nil) })
+
+	return ks
+}
+
+func signTestToken(t *testing.T, method jwt.SigningMethod, kid string, claims jwt.Claims, key interface{}) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newRSAKey(t *testing.T) crypto.Signer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func newECKey(t *testing.T) crypto.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func newEd25519Key(t *testing.T) crypto.Signer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func marshalPrivateKey(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func writePrivateKey(t *testing.T, key crypto.Signer) string {
+	path := filepath.Join(t.TempDir(), "private.pem")
+	require.NoError(t, os.WriteFile(path, marshalPrivateKey(t, key), 0o600))
+	return path
+}
+
+func writePublicKey(t *testing.T, public crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "public.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func thumbprint(t *testing.T, public crypto.PublicKey) string {
+	kid, err := keyThumbprint(public)
+	require.NoError(t, err)
+	return kid
+}
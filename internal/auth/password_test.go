@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("password1")
+	require.NoError(t, err)
+	assert.NotEqual(t, "password1", hash)
+
+	ok, err := CheckPassword(hash, "password1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = CheckPassword(hash, "password2")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestCheckPassword_EmptyHash(t *testing.T) {
+	ok, err := CheckPassword("", "dummy password")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"crypto/x509"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+)
+
+// Scopes granted to access tokens.
+const (
+	ScopeCompaniesRead   = "companies:read"
+	ScopeCompaniesWrite  = "companies:write"
+	ScopeCompaniesDelete = "companies:delete"
+	ScopeUsersManage     = "users:manage"
+)
+
+// Roles of users.
+const (
+	RoleViewer  = "viewer"
+	RoleEditor  = "editor"
+	RoleManager = "manager"
+	RoleAdmin   = "admin"
+)
+
+// roleScopes maps roles to the scopes they grant.
+var roleScopes = map[string][]string{
+	RoleViewer:  {ScopeCompaniesRead},
+	RoleEditor:  {ScopeCompaniesRead, ScopeCompaniesWrite},
+	RoleManager: {ScopeCompaniesRead, ScopeCompaniesWrite, ScopeCompaniesDelete},
+	RoleAdmin:   {ScopeCompaniesRead, ScopeCompaniesWrite, ScopeCompaniesDelete, ScopeUsersManage},
+}
+
+// ScopesForRoles returns the union of the scopes granted by the roles in a stable order. Unknown roles grant nothing.
+func ScopesForRoles(roles []string) []string {
+	granted := make(map[string]bool)
+	for _, role := range roles {
+		for _, scope := range roleScopes[role] {
+			granted[scope] = true
+		}
+	}
+
+	scopes := make([]string, 0, len(granted))
+	for _, scope := range []string{ScopeCompaniesRead, ScopeCompaniesWrite, ScopeCompaniesDelete, ScopeUsersManage} {
+		if granted[scope] {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// CertificatePrincipal maps a verified client certificate to a principal. The subject common name is the name
+// of the principal and organizational units naming roles are its roles, e.g. "CN=billing,OU=editor".
+func CertificatePrincipal(cert *x509.Certificate) *models.Principal {
+	var roles []string
+	for _, unit := range cert.Subject.OrganizationalUnit {
+		if _, ok := roleScopes[unit]; ok {
+			roles = append(roles, unit)
+		}
+	}
+
+	return &models.Principal{
+		Type:   models.PrincipalCertificate,
+		ID:     cert.Subject.String(),
+		Name:   cert.Subject.CommonName,
+		Roles:  roles,
+		Scopes: ScopesForRoles(roles),
+	}
+}
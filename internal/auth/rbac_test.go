@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopesForRoles(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []string
+		want  []string
+	}{
+		{name: "no roles", roles: nil, want: []string{}},
+		{name: "viewer", roles: []string{RoleViewer}, want: []string{ScopeCompaniesRead}},
+		{name: "editor", roles: []string{RoleEditor}, want: []string{ScopeCompaniesRead, ScopeCompaniesWrite}},
+		{
+			name:  "manager",
+			roles: []string{RoleManager},
+			want:  []string{ScopeCompaniesRead, ScopeCompaniesWrite, ScopeCompaniesDelete},
+		},
+		{
+			name:  "admin",
+			roles: []string{RoleAdmin},
+			want:  []string{ScopeCompaniesRead, ScopeCompaniesWrite, ScopeCompaniesDelete, ScopeUsersManage},
+		},
+		{
+			name:  "union",
+			roles: []string{RoleViewer, RoleEditor, "unknown"},
+			want:  []string{ScopeCompaniesRead, ScopeCompaniesWrite},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ScopesForRoles(tt.roles))
+		})
+	}
+}
+
+func TestCertificatePrincipal(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{
+		CommonName:         "billing",
+		Organization:       []string{"Example"},
+		OrganizationalUnit: []string{"finance", RoleEditor},
+	}}
+
+	principal := CertificatePrincipal(cert)
+	assert.Equal(t, models.PrincipalCertificate, principal.Type)
+	assert.Equal(t, "CN=billing,OU=finance+OU=editor,O=Example", principal.ID)
+	assert.Equal(t, "billing", principal.Name)
+	assert.Equal(t, []string{RoleEditor}, principal.Roles) // Units that aren't roles are ignored
+	assert.Equal(t, []string{ScopeCompaniesRead, ScopeCompaniesWrite}, principal.Scopes)
+}
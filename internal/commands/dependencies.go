@@ -0,0 +1,16 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+)
+
+// Service describes the service methods required for the command handler.
+type Service interface {
+	CreateCompany(ctx context.Context, company *models.Company) error
+	UpdateCompany(ctx context.Context, companyPatch *models.CompanyPatch) error
+	DeleteCompany(ctx context.Context, companyUUID string, version *int) error
+}
+
+//go:generate mockgen -destination=./mocks/service_mock.go -package=mocks . Service
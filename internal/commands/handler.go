@@ -0,0 +1,104 @@
+// Package commands handles company commands consumed from Kafka.
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ezhdanovskiy/companies/internal/dto"
+	"github.com/ezhdanovskiy/companies/internal/kafka"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Command types.
+const (
+	CommandCreate = "create"
+	CommandUpdate = "update"
+	CommandDelete = "delete"
+)
+
+// Command is a request to change a company. Data has the same format as the body of the corresponding HTTP request.
+type Command struct {
+	Type string          `json:"type"`
+	ID   string          `json:"id"`
+	Data json.RawMessage `json:"data"`
+}
+
+type Handler struct {
+	log *zap.SugaredLogger
+	svc Service
+}
+
+func NewHandler(logger *zap.SugaredLogger, svc Service) *Handler {
+	return &Handler{
+		log: logger,
+		svc: svc,
+	}
+}
+
+// Handle runs the command from the message through the service.
+// Malformed commands and commands that can't be applied are reported as kafka.ErrPoisonMessage.
+func (h *Handler) Handle(ctx context.Context, m *kafka.Message) error {
+	if correlationID := m.Headers[kafka.HeaderCorrelationID]; correlationID != "" {
+		ctx = requestctx.WithCorrelationID(ctx, correlationID)
+	}
+
+	var cmd Command
+	if err := json.Unmarshal(m.Body, &cmd); err != nil {
+		return fmt.Errorf("%w: decode command: %s", kafka.ErrPoisonMessage, err)
+	}
+
+	uid := strings.ToLower(cmd.ID)
+	if _, err := uuid.Parse(uid); err != nil {
+		return fmt.Errorf("%w: invalid id: %s", kafka.ErrPoisonMessage, err)
+	}
+	h.log.With("type", cmd.Type, "uuid", uid).Debug("Handler.Handle")
+
+	err := h.handle(ctx, &cmd, uid)
+	if errors.Is(err, models.ErrCompanyNotFound) || errors.Is(err, models.ErrAlreadyExists) ||
+		errors.Is(err, models.ErrInvalidReference) {
+		return fmt.Errorf("%w: %s", kafka.ErrPoisonMessage, err)
+	}
+
+	return err
+}
+
+func (h *Handler) handle(ctx context.Context, cmd *Command, uid string) error {
+	switch cmd.Type {
+	case CommandCreate:
+		var data dto.CompanyData
+		if err := decodeData(cmd.Data, &data); err != nil {
+			return err
+		}
+		return h.svc.CreateCompany(ctx, data.ToDomain(uid))
+
+	case CommandUpdate:
+		var data dto.CompanyPatch
+		if err := decodeData(cmd.Data, &data); err != nil {
+			return err
+		}
+		return h.svc.UpdateCompany(ctx, data.ToDomain(uid))
+
+	case CommandDelete:
+		return h.svc.DeleteCompany(ctx, uid, nil)
+	}
+
+	return fmt.Errorf("%w: unknown command type %q", kafka.ErrPoisonMessage, cmd.Type)
+}
+
+// decodeData decodes and validates the data of the command.
+func decodeData(data json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: decode command data: %s", kafka.ErrPoisonMessage, err)
+	}
+	if err := dto.Validate(v); err != nil {
+		return fmt.Errorf("%w: %s", kafka.ErrPoisonMessage, err)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ezhdanovskiy/companies/internal/commands/mocks"
+	"github.com/ezhdanovskiy/companies/internal/kafka"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testUUID = "abc8c242-00ed-40a6-82df-ea0d3afd0867"
+
+func TestHandler_Create(t *testing.T) {
+	th := newTestHandler(t)
+	defer th.Finish()
+
+	th.mockSvc.EXPECT().CreateCompany(gomock.Any(), &models.Company{
+		ID:              testUUID,
+		Name:            "XM67",
+		EmployeesAmount: 123,
+		Registered:      true,
+		Type:            "Corporations",
+	}).DoAndReturn(func(ctx context.Context, _ *models.Company) error {
+		assert.Equal(t, "request-id", requestctx.CorrelationID(ctx))
+		return nil
+	})
+
+	err := th.handler.Handle(context.Background(), &kafka.Message{
+		Headers: kafka.Headers{kafka.HeaderCorrelationID: "request-id"},
+		Body: []byte(`{"type":"create","id":"` + testUUID + `","data":` +
+			`{"name":"XM67","employees_amount":123,"registered":true,"type":"Corporations"}}`),
+	})
+	require.NoError(t, err)
+}
+
+func TestHandler_Create_ZeroValues(t *testing.T) {
+	th := newTestHandler(t)
+	defer th.Finish()
+
+	th.mockSvc.EXPECT().CreateCompany(gomock.Any(), &models.Company{
+		ID:              testUUID,
+		Name:            "XM67",
+		EmployeesAmount: 0,
+		Registered:      false,
+		Type:            "Corporations",
+	}).Return(nil)
+
+	err := th.handler.Handle(context.Background(), &kafka.Message{
+		Body: []byte(`{"type":"create","id":"` + testUUID + `","data":` +
+			`{"name":"XM67","employees_amount":0,"registered":false,"type":"Corporations"}}`),
+	})
+	require.NoError(t, err)
+}
+
+func TestHandler_Create_MissingFields(t *testing.T) {
+	th := newTestHandler(t)
+	defer th.Finish()
+
+	for _, data := range []string{
+		`{"name":"XM67","registered":false,"type":"Corporations"}`,
+		`{"name":"XM67","employees_amount":0,"type":"Corporations"}`,
+	} {
+		err := th.handler.Handle(context.Background(), &kafka.Message{
+			Body: []byte(`{"type":"create","id":"` + testUUID + `","data":` + data + `}`),
+		})
+		assert.ErrorIs(t, err, kafka.ErrPoisonMessage, data)
+	}
+}
+
+func TestHandler_Create_Invalid(t *testing.T) {
+	th := newTestHandler(t)
+	defer th.Finish()
+
+	err := th.handler.Handle(context.Background(), &kafka.Message{
+		Body: []byte(`{"type":"create","id":"` + testUUID + `","data":{"name":"XM67","type":"Unknown"}}`),
+	})
+	assert.ErrorIs(t, err, kafka.ErrPoisonMessage)
+}
+
+func TestHandler_Update(t *testing.T) {
+	th := newTestHandler(t)
+	defer th.Finish()
+
+	th.mockSvc.EXPECT().UpdateCompany(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, patch *models.CompanyPatch) error {
+			assert.Equal(t, testUUID, patch.ID)
+			require.NotNil(t, patch.EmployeesAmount)
+			assert.Equal(t, 66, *patch.EmployeesAmount)
+			assert.Nil(t, patch.Name)
+			return nil
+		})
+
+	err := th.handler.Handle(context.Background(), &kafka.Message{
+		Body: []byte(`{"type":"update","id":"` + testUUID + `","data":{"employees_amount":66}}`),
+	})
+	require.NoError(t, err)
+}
+
+func TestHandler_Update_NotFound(t *testing.T) {
+	th := newTestHandler(t)
+	defer th.Finish()
+
+	th.mockSvc.EXPECT().UpdateCompany(gomock.Any(), gomock.Any()).
+		Return(models.ErrCompanyNotFound)
+
+	err := th.handler.Handle(context.Background(), &kafka.Message{
+		Body: []byte(`{"type":"update","id":"` + testUUID + `","data":{}}`),
+	})
+	assert.ErrorIs(t, err, kafka.ErrPoisonMessage)
+}
+
+func TestHandler_Delete(t *testing.T) {
+	th := newTestHandler(t)
+	defer th.Finish()
+
+	th.mockSvc.EXPECT().DeleteCompany(gomock.Any(), testUUID, nil).
+		Return(nil)
+
+	err := th.handler.Handle(context.Background(), &kafka.Message{
+		Body: []byte(`{"type":"delete","id":"` + testUUID + `"}`),
+	})
+	require.NoError(t, err)
+}
+
+func TestHandler_Delete_Error(t *testing.T) {
+	th := newTestHandler(t)
+	defer th.Finish()
+
+	expectedErr := errors.New("DeleteCompanyError")
+	th.mockSvc.EXPECT().DeleteCompany(gomock.Any(), testUUID, nil).
+		Return(expectedErr)
+
+	err := th.handler.Handle(context.Background(), &kafka.Message{
+		Body: []byte(`{"type":"delete","id":"` + testUUID + `"}`),
+	})
+	assert.Equal(t, expectedErr, err) // transient errors are retried
+}
+
+func TestHandler_Create_AlreadyExists(t *testing.T) {
+	th := newTestHandler(t)
+	defer th.Finish()
+
+	th.mockSvc.EXPECT().CreateCompany(gomock.Any(), gomock.Any()).
+		Return(fmt.Errorf("insert company: %w", models.ErrAlreadyExists))
+
+	err := th.handler.Handle(context.Background(), &kafka.Message{
+		Body: []byte(`{"type":"create","id":"` + testUUID + `","data":` +
+			`{"name":"XM67","employees_amount":123,"registered":true,"type":"Corporations"}}`),
+	})
+	assert.ErrorIs(t, err, kafka.ErrPoisonMessage) // Retries would violate the constraint again
+}
+
+func TestHandler_Poison(t *testing.T) {
+	th := newTestHandler(t)
+	defer th.Finish()
+
+	for _, body := range []string{
+		`not json`,
+		`{"type":"delete","id":"not-uuid"}`,
+		`{"type":"rename","id":"` + testUUID + `"}`,
+		`{"type":"create","id":"` + testUUID + `","data":"not object"}`,
+	} {
+		err := th.handler.Handle(context.Background(), &kafka.Message{Body: []byte(body)})
+		assert.ErrorIs(t, err, kafka.ErrPoisonMessage, body)
+	}
+}
+
+// TestHandler ---------------------------------------------------------------------------------------------------------
+type TestHandler struct {
+	mockCtrl *gomock.Controller
+	mockSvc  *mocks.MockService
+	handler  *Handler
+}
+
+func newTestHandler(t *testing.T) TestHandler {
+	t.Parallel()
+	mockCtrl := gomock.NewController(t)
+	th := TestHandler{
+		mockCtrl: mockCtrl,
+		mockSvc:  mocks.NewMockService(mockCtrl),
+	}
+	th.handler = NewHandler(zap.NewNop().Sugar(), th.mockSvc)
+
+	return th
+}
+
+func (th *TestHandler) Finish() {
+	th.mockCtrl.Finish()
+}
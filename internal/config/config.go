@@ -9,12 +9,29 @@ import (
 
 // Config contains all parameter for configuring application.
 type Config struct {
-	LogLevel    string `mapstructure:"log_level"`
-	LogEncoding string `mapstructure:"log_encoding"` // json/console
-	HTTPPort    int    `mapstructure:"http_port"`
-	DB          DB
-	Kafka       Kafka
-	JWTKey      string `mapstructure:"jwt_key"`
+	LogLevel            string        `mapstructure:"log_level"`
+	LogEncoding         string        `mapstructure:"log_encoding"` // json/console
+	HTTPPort            int           `mapstructure:"http_port"`
+	HTTPShutdownDelay   time.Duration `mapstructure:"http_shutdown_delay"` // connections are accepted this long after /readyz fails
+	HTTPShutdownTimeout time.Duration `mapstructure:"http_shutdown_timeout"`
+	HTTPTrustedProxies  []string      `mapstructure:"http_trusted_proxies"` // forwarding headers are honored only from them
+	HealthCheckTimeout  time.Duration `mapstructure:"health_check_timeout"`
+	DB                  DB
+	Kafka               Kafka
+	Outbox              Outbox
+	Purge               Purge
+	Tracing             Tracing
+	JWT                 JWT
+	TLS                 TLS
+	JWTKey              string        `mapstructure:"jwt_key"` // HS256 secret
+	RefreshTokenTTL     time.Duration `mapstructure:"refresh_token_ttl"`
+	IdempotencyKeyTTL   time.Duration `mapstructure:"idempotency_key_ttl"`  // responses to Idempotency-Key are replayed for this long
+	IdempotencyLockTTL  time.Duration `mapstructure:"idempotency_lock_ttl"` // a request in progress longer may be retried
+
+	// Administrator created on startup if there is no user with such name, skipped if empty.
+	AdminUsername string `mapstructure:"admin_username"`
+	AdminEmail    string `mapstructure:"admin_email"`
+	AdminPassword string `mapstructure:"admin_password"`
 }
 
 // DB contains parameter for configuring repository.
@@ -32,7 +49,62 @@ type Kafka struct {
 	Addr         string        `mapstructure:"kafka_addr"`
 	Topic        string        `mapstructure:"kafka_topic"`
 	BatchSize    int           `mapstructure:"kafka_batch_size"`
-	BatchTimeout time.Duration `mapstructure:"kafka_batch_timeout"`
+	BatchTimeout time.Duration `mapstructure:"kafka_batch_timeout"` // synchronous publishing waits for it unless the batch is full
+	Delivery     string        `mapstructure:"kafka_delivery"`      // none/leader/all
+	MaxAttempts  int           `mapstructure:"kafka_max_attempts"`
+	Compression  string        `mapstructure:"kafka_compression"`  // none/gzip/snappy/lz4/zstd
+	EventFormat  string        `mapstructure:"kafka_event_format"` // structured/binary
+	EventSource  string        `mapstructure:"kafka_event_source"`
+
+	CommandsTopic        string        `mapstructure:"kafka_commands_topic"` // consumer is disabled if empty
+	ConsumerGroup        string        `mapstructure:"kafka_consumer_group"`
+	DeadLetterTopic      string        `mapstructure:"kafka_dead_letter_topic"`
+	ConsumerMaxRetries   int           `mapstructure:"kafka_consumer_max_retries"`
+	ConsumerRetryBackoff time.Duration `mapstructure:"kafka_consumer_retry_backoff"`
+}
+
+// Outbox contains parameter for configuring outbox relay.
+type Outbox struct {
+	PollInterval time.Duration `mapstructure:"outbox_poll_interval"`
+	BatchSize    int           `mapstructure:"outbox_batch_size"`
+	BackoffMin   time.Duration `mapstructure:"outbox_backoff_min"`
+	BackoffMax   time.Duration `mapstructure:"outbox_backoff_max"`
+}
+
+// Purge contains parameter for configuring purging of deleted companies.
+type Purge struct {
+	Interval  time.Duration `mapstructure:"purge_interval"`
+	Retention time.Duration `mapstructure:"purge_retention"` // purging is disabled if 0
+	BatchSize int           `mapstructure:"purge_batch_size"`
+}
+
+// Tracing contains parameter for configuring OpenTelemetry tracing.
+type Tracing struct {
+	Exporter     string  `mapstructure:"tracing_exporter"` // none/stdout/otlp
+	OTLPEndpoint string  `mapstructure:"tracing_otlp_endpoint"`
+	ServiceName  string  `mapstructure:"tracing_service_name"`
+	SampleRatio  float64 `mapstructure:"tracing_sample_ratio"`
+}
+
+// JWT contains parameter for configuring signing and verification of access tokens.
+type JWT struct {
+	Algorithm            string        `mapstructure:"jwt_algorithm"`        // HS256/RS256/ES256/EdDSA
+	SigningKeyFile       string        `mapstructure:"jwt_signing_key_file"` // PEM private key
+	VerificationKeyFiles []string      `mapstructure:"jwt_verification_key_files"`
+	JWKSURL              string        `mapstructure:"jwt_jwks_url"`
+	KeyGracePeriod       time.Duration `mapstructure:"jwt_key_grace_period"`
+	KeysRefreshInterval  time.Duration `mapstructure:"jwt_keys_refresh_interval"`
+}
+
+// TLS contains parameter for configuring HTTPS, the server is plain HTTP if CertFile is empty.
+type TLS struct {
+	CertFile       string        `mapstructure:"tls_cert_file"`
+	KeyFile        string        `mapstructure:"tls_key_file"`
+	MinVersion     string        `mapstructure:"tls_min_version"` // 1.2/1.3
+	CipherSuites   []string      `mapstructure:"tls_cipher_suites"`
+	ClientCAFile   string        `mapstructure:"tls_client_ca_file"`
+	ClientAuth     string        `mapstructure:"tls_client_auth"` // none/optional/require
+	ReloadInterval time.Duration `mapstructure:"tls_reload_interval"`
 }
 
 // NewConfig creates a new Config instance with parameters parsed by viber.
@@ -44,6 +116,12 @@ func NewConfig() (*Config, error) {
 	viper.SetDefault("log_level", "debug")
 	viper.SetDefault("log_encoding", "console")
 	viper.SetDefault("http_port", 8080) //nolint:gomnd
+	viper.SetDefault("http_shutdown_delay", "5s")
+	viper.SetDefault("http_shutdown_timeout", "10s")
+	viper.SetDefault("http_trusted_proxies", []string{})
+	viper.SetDefault("health_check_timeout", "2s")
+	viper.SetDefault("idempotency_key_ttl", "24h")
+	viper.SetDefault("idempotency_lock_ttl", "1m")
 
 	viper.SetDefault("db_host", "localhost")
 	viper.SetDefault("db_port", 5432) //nolint:gomnd
@@ -54,10 +132,52 @@ func NewConfig() (*Config, error) {
 
 	viper.SetDefault("kafka_addr", "127.0.0.1:9092")
 	viper.SetDefault("kafka_topic", "companies-mutations")
-	viper.SetDefault("kafka_batch_size", 3) //nolint:gomnd,nolintlint
-	viper.SetDefault("kafka_batch_timeout", "10s")
+	viper.SetDefault("kafka_batch_size", 100) //nolint:gomnd
+	viper.SetDefault("kafka_batch_timeout", "10ms")
+	viper.SetDefault("kafka_delivery", "all")
+	viper.SetDefault("kafka_max_attempts", 10) //nolint:gomnd
+	viper.SetDefault("kafka_compression", "none")
+	viper.SetDefault("kafka_event_format", "structured")
+	viper.SetDefault("kafka_event_source", "/companies")
+	viper.SetDefault("kafka_commands_topic", "")
+	viper.SetDefault("kafka_consumer_group", "companies")
+	viper.SetDefault("kafka_dead_letter_topic", "companies-commands-dlq")
+	viper.SetDefault("kafka_consumer_max_retries", 3) //nolint:gomnd
+	viper.SetDefault("kafka_consumer_retry_backoff", "1s")
+
+	viper.SetDefault("outbox_poll_interval", "1s")
+	viper.SetDefault("outbox_batch_size", 100) //nolint:gomnd
+	viper.SetDefault("outbox_backoff_min", "1s")
+	viper.SetDefault("outbox_backoff_max", "1m")
+
+	viper.SetDefault("purge_interval", "1h")
+	viper.SetDefault("purge_retention", "720h")
+	viper.SetDefault("purge_batch_size", 100) //nolint:gomnd
+
+	viper.SetDefault("tracing_exporter", "none")
+	viper.SetDefault("tracing_otlp_endpoint", "localhost:4318")
+	viper.SetDefault("tracing_service_name", "companies")
+	viper.SetDefault("tracing_sample_ratio", 1.0)
+
+	viper.SetDefault("tls_cert_file", "")
+	viper.SetDefault("tls_key_file", "")
+	viper.SetDefault("tls_min_version", "1.2")
+	viper.SetDefault("tls_cipher_suites", []string{})
+	viper.SetDefault("tls_client_ca_file", "")
+	viper.SetDefault("tls_client_auth", "none")
+	viper.SetDefault("tls_reload_interval", "1m")
 
 	viper.SetDefault("jwt_key", "supersecretkey")
+	viper.SetDefault("jwt_algorithm", "HS256")
+	viper.SetDefault("jwt_signing_key_file", "")
+	viper.SetDefault("jwt_verification_key_files", []string{})
+	viper.SetDefault("jwt_jwks_url", "")
+	viper.SetDefault("jwt_key_grace_period", "1h")
+	viper.SetDefault("jwt_keys_refresh_interval", "5m")
+	viper.SetDefault("refresh_token_ttl", "720h")
+	viper.SetDefault("admin_username", "")
+	viper.SetDefault("admin_email", "")
+	viper.SetDefault("admin_password", "")
 
 	_ = viper.ReadInConfig()
 
@@ -73,5 +193,25 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
+	if err := viper.Unmarshal(&config.Outbox); err != nil {
+		return nil, err
+	}
+
+	if err := viper.Unmarshal(&config.Purge); err != nil {
+		return nil, err
+	}
+
+	if err := viper.Unmarshal(&config.Tracing); err != nil {
+		return nil, err
+	}
+
+	if err := viper.Unmarshal(&config.JWT); err != nil {
+		return nil, err
+	}
+
+	if err := viper.Unmarshal(&config.TLS); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
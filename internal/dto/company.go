@@ -0,0 +1,59 @@
+// Package dto holds the company data accepted by the HTTP API and Kafka commands with its validation rules.
+package dto
+
+import (
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/go-playground/validator/v10"
+)
+
+// The rules use the tag of gin's validator, so the HTTP layer applies them when binding a request body.
+var validate = func() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	return v
+}()
+
+// Validate checks data with the rules from its binding tags.
+func Validate(data interface{}) error {
+	return validate.Struct(data)
+}
+
+// CompanyData is the data of a new company. Pointers tell missing fields from false and zero values.
+type CompanyData struct {
+	Name            string `json:"name" binding:"required,max=15"`
+	Description     string `json:"description" binding:"omitempty,max=3000"`
+	EmployeesAmount *int   `json:"employees_amount" binding:"required"`
+	Registered      *bool  `json:"registered" binding:"required"`
+	Type            string `json:"type" binding:"required,oneof=Corporations NonProfit Cooperative 'Sole Proprietorship'"`
+}
+
+func (d *CompanyData) ToDomain(uid string) *models.Company {
+	return &models.Company{
+		ID:              uid,
+		Name:            d.Name,
+		Description:     d.Description,
+		EmployeesAmount: *d.EmployeesAmount,
+		Registered:      *d.Registered,
+		Type:            d.Type,
+	}
+}
+
+// CompanyPatch is the data of a company update, only the fields that are set are changed.
+type CompanyPatch struct {
+	Name            *string `json:"name" binding:"omitempty,max=15"`
+	Description     *string `json:"description" binding:"omitempty,max=3000"`
+	EmployeesAmount *int    `json:"employees_amount" binding:"omitempty"`
+	Registered      *bool   `json:"registered" binding:"omitempty"`
+	Type            *string `json:"type" binding:"omitempty,oneof=Corporations NonProfit Cooperative 'Sole Proprietorship'"`
+}
+
+func (d *CompanyPatch) ToDomain(uid string) *models.CompanyPatch {
+	return &models.CompanyPatch{
+		ID:              uid,
+		Name:            d.Name,
+		Description:     d.Description,
+		EmployeesAmount: d.EmployeesAmount,
+		Registered:      d.Registered,
+		Type:            d.Type,
+	}
+}
@@ -0,0 +1,119 @@
+// Package health runs readiness checks of the application dependencies.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of checks and reports.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check returns an error if the dependency is not ready.
+type Check func(ctx context.Context) error
+
+// CheckResult is the outcome of a single check.
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the outcome of all checks.
+type Report struct {
+	Status       string                  `json:"status"`
+	ShuttingDown bool                    `json:"shutting_down,omitempty"`
+	Checks       map[string]*CheckResult `json:"checks"`
+}
+
+type namedCheck struct {
+	name    string
+	timeout time.Duration
+	check   Check
+}
+
+// Checker runs registered checks concurrently, each limited by its own timeout.
+type Checker struct {
+	checks       []namedCheck
+	shuttingDown int32
+}
+
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// Add registers a check. It must be called before the checker is used.
+func (c *Checker) Add(name string, timeout time.Duration, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, timeout: timeout, check: check})
+}
+
+// SetShuttingDown makes the checker report failure regardless of the checks.
+func (c *Checker) SetShuttingDown() {
+	atomic.StoreInt32(&c.shuttingDown, 1)
+}
+
+// Check runs all checks and returns the report. The report status is ok only if all checks pass.
+func (c *Checker) Check(ctx context.Context) *Report {
+	report := &Report{
+		Status:       StatusOK,
+		ShuttingDown: atomic.LoadInt32(&c.shuttingDown) == 1,
+		Checks:       make(map[string]*CheckResult, len(c.checks)),
+	}
+
+	results := make([]*CheckResult, len(c.checks))
+	var wg sync.WaitGroup
+	for i := range c.checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = run(ctx, &c.checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i, res := range results {
+		report.Checks[c.checks[i].name] = res
+		if res.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	if report.ShuttingDown {
+		report.Status = StatusFail
+	}
+
+	return report
+}
+
+func run(ctx context.Context, nc *namedCheck) *CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, nc.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- nc.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := &CheckResult{
+		Status:   StatusOK,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+
+	return res
+}
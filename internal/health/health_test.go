@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker_OK(t *testing.T) {
+	c := NewChecker()
+	c.Add("db", time.Second, func(ctx context.Context) error { return nil })
+	c.Add("kafka", time.Second, func(ctx context.Context) error { return nil })
+
+	report := c.Check(context.Background())
+	assert.Equal(t, StatusOK, report.Status)
+	assert.False(t, report.ShuttingDown)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, StatusOK, report.Checks["db"].Status)
+	assert.Equal(t, StatusOK, report.Checks["kafka"].Status)
+}
+
+func TestChecker_Fail(t *testing.T) {
+	c := NewChecker()
+	c.Add("db", time.Second, func(ctx context.Context) error { return nil })
+	c.Add("migrations", time.Second, func(ctx context.Context) error { return errors.New("dirty") })
+
+	report := c.Check(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["db"].Status)
+	assert.Equal(t, StatusFail, report.Checks["migrations"].Status)
+	assert.Equal(t, "dirty", report.Checks["migrations"].Error)
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	c := NewChecker()
+	c.Add("kafka", 10*time.Millisecond, func(ctx context.Context) error {
+		time.Sleep(time.Second) // ignores the context
+		return nil
+	})
+
+	start := time.Now()
+	report := c.Check(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["kafka"].Error)
+}
+
+func TestChecker_ShuttingDown(t *testing.T) {
+	c := NewChecker()
+	c.Add("db", time.Second, func(ctx context.Context) error { return nil })
+	c.SetShuttingDown()
+
+	report := c.Check(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.True(t, report.ShuttingDown)
+	assert.Equal(t, StatusOK, report.Checks["db"].Status)
+}
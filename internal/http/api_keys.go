@@ -0,0 +1,120 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ezhdanovskiy/companies/internal/http/requests"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (s *Server) CreateAPIKey(c *gin.Context) {
+	s.log.Debug("Server.CreateAPIKey")
+
+	var req requests.CreateAPIKey
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	created, err := s.svc.CreateAPIKey(c.Request.Context(), req.ToDomain())
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		if abortDBError(c, err) {
+			return
+		}
+
+		s.abortInternalError(c, err)
+		return
+	}
+
+	resp := apiKeyResponse(created.APIKey)
+	resp["key"] = created.Key // The key is shown only once
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (s *Server) ListAPIKeys(c *gin.Context) {
+	s.log.Debug("Server.ListAPIKeys")
+
+	keys, err := s.svc.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		s.abortInternalError(c, err)
+		return
+	}
+
+	items := make([]gin.H, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, apiKeyResponse(key))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"api_keys": items,
+	})
+}
+
+func (s *Server) RevokeAPIKey(c *gin.Context) {
+	id := strings.ToLower(c.Param("id"))
+
+	if _, err := uuid.Parse(id); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	s.log.With("id", id).Debug("Server.RevokeAPIKey")
+
+	err := s.svc.RevokeAPIKey(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"message": "API key not found",
+			})
+			return
+		}
+		if errors.Is(err, models.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		if abortDBError(c, err) {
+			return
+		}
+
+		s.abortInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// apiKeyResponse describes the key without its hash.
+func apiKeyResponse(key *models.APIKey) gin.H {
+	return gin.H{
+		"id":           key.ID,
+		"name":         key.Name,
+		"prefix":       key.Prefix,
+		"scopes":       key.Scopes,
+		"created_by":   key.CreatedBy,
+		"expires_at":   key.ExpiresAt,
+		"last_used_at": key.LastUsedAt,
+		"revoked_at":   key.RevokedAt,
+		"created_at":   key.CreatedAt,
+	}
+}
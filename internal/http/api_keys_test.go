@@ -0,0 +1,49 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ezhdanovskiy/companies/internal/http/mocks"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestServer_RevokeAPIKey(t *testing.T) {
+	const id = "abc8c242-00ed-40a6-82df-ea0d3afd0867"
+
+	tests := []struct {
+		name     string
+		id       string
+		svcErr   error
+		wantCode int
+	}{
+		{name: "revoked", id: id, wantCode: http.StatusOK},
+		{name: "upper case id", id: "ABC8C242-00ED-40A6-82DF-EA0D3AFD0867", wantCode: http.StatusOK},
+		{name: "unknown id", id: id, svcErr: models.ErrAPIKeyNotFound, wantCode: http.StatusNotFound},
+		{name: "invalid id", id: "not-uuid", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := mocks.NewMockService(ctrl)
+			if tt.wantCode != http.StatusBadRequest {
+				svc.EXPECT().RevokeAPIKey(gomock.Any(), id).Return(tt.svcErr)
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			s := &Server{log: zap.NewNop().Sugar(), svc: svc}
+			router.DELETE("/admin/api-keys/:id", s.RevokeAPIKey)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/admin/api-keys/"+tt.id, nil))
+			assert.Equal(t, tt.wantCode, recorder.Code)
+		})
+	}
+}
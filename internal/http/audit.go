@@ -0,0 +1,72 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ezhdanovskiy/companies/internal/http/requests"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (s *Server) GetCompanyAudit(c *gin.Context) {
+	uid := strings.ToLower(c.Param("uuid"))
+
+	if _, err := uuid.Parse(uid); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	var req requests.ListAudit
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	filter := req.ToDomain(uid)
+	s.log.With("filter", filter).Debug("Server.GetCompanyAudit")
+
+	records, err := s.svc.GetCompanyAudit(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		s.abortInternalError(c, err)
+		return
+	}
+
+	items := make([]gin.H, 0, len(records))
+	for _, record := range records {
+		items = append(items, auditRecordResponse(record))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"audit":  items,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+func auditRecordResponse(record *models.AuditRecord) gin.H {
+	return gin.H{
+		"id":         record.ID,
+		"company_id": record.CompanyID,
+		"action":     record.Action,
+		"actor":      record.Actor,
+		"actor_type": record.ActorType,
+		"actor_id":   record.ActorID,
+		"before":     record.Before,
+		"after":      record.After,
+		"request_id": record.RequestID,
+		"client_ip":  record.ClientIP,
+		"created_at": record.CreatedAt,
+	}
+}
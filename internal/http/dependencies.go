@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"time"
 
 	"github.com/ezhdanovskiy/companies/internal/models"
 	_ "github.com/golang/mock/mockgen/model"
@@ -11,8 +12,32 @@ import (
 type Service interface {
 	CreateCompany(ctx context.Context, company *models.Company) error
 	UpdateCompany(ctx context.Context, companyPatch *models.CompanyPatch) error
-	DeleteCompany(ctx context.Context, companyUUID string) error
+	DeleteCompany(ctx context.Context, companyUUID string, version *int) error
+	RestoreCompany(ctx context.Context, companyUUID string) (*models.Company, error)
 	GetCompany(ctx context.Context, companyUUID string) (*models.Company, error)
+	ListCompanies(ctx context.Context, filter *models.CompanyFilter) (*models.CompanyList, error)
+	ListCompanyVersions(ctx context.Context, filter *models.VersionFilter) ([]*models.CompanyVersion, error)
+	GetCompanyVersion(ctx context.Context, companyUUID string, version int) (*models.CompanyVersion, error)
+	GetCompanyAsOf(ctx context.Context, companyUUID string, asOf time.Time) (*models.Company, error)
+	RevertCompany(ctx context.Context, companyUUID string, version int) (*models.Company, error)
+	GetCompanyAudit(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditRecord, error)
+
+	Login(ctx context.Context, username, password string) (*models.Tokens, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*models.Tokens, error)
+	Logout(ctx context.Context, access *models.AccessToken, refreshToken string) error
+	RevokeToken(ctx context.Context, jti string) error
+	IsTokenRevoked(ctx context.Context, jti, userID string) (bool, error)
+	CreateUser(ctx context.Context, user *models.NewUser) (*models.User, error)
+	DisableUser(ctx context.Context, username string) error
+	EnableUser(ctx context.Context, username string) error
+
+	CreateAPIKey(ctx context.Context, key *models.NewAPIKey) (*models.CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.Principal, error)
+
+	BeginIdempotentRequest(ctx context.Context, key, fingerprint string) (*models.IdempotentRequest, error)
+	CompleteIdempotentRequest(ctx context.Context, req *models.IdempotentRequest, resp *models.IdempotentResponse) error
 }
 
 //go:generate mockgen -destination=./mocks/service_mock.go -package=mocks . Service
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Codes of errors answered to clients. Unlike messages, they are stable.
+const (
+	codeAlreadyExists    = "already_exists"
+	codeConflict         = "conflict"
+	codeInvalidReference = "invalid_reference"
+	codeInternal         = "internal"
+)
+
+// abortDBError answers constraint violations and conflicts of the database with their codes,
+// the SQL message isn't exposed. It reports whether err is such an error.
+// Conflicts are transient, they are answered with 503, so responses to idempotency keys aren't stored for them.
+func abortDBError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, models.ErrAlreadyExists):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"code":    codeAlreadyExists,
+			"message": "Resource with the same unique fields already exists",
+		})
+	case errors.Is(err, models.ErrConflict):
+		c.Header("Retry-After", "1")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"code":    codeConflict,
+			"message": "Conflict with a concurrent change, retry the request",
+		})
+	case errors.Is(err, models.ErrInvalidReference):
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"code":    codeInvalidReference,
+			"message": "Referenced value does not exist",
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// abortInternalError answers unexpected errors with a generic message, the error is only logged.
+func (s *Server) abortInternalError(c *gin.Context, err error) {
+	s.log.With("error", err, "method", c.Request.Method, "path", c.FullPath()).Error("Request failed")
+	c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+		"code":    codeInternal,
+		"message": "Internal server error",
+	})
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// companyETag is the strong entity tag of a company version.
+func companyETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// notModified tells whether the If-None-Match header of the request matches the entity tag.
+// Tags are compared weakly, so W/"1" matches "1".
+func notModified(c *gin.Context, etag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion parses the company version from the If-Match header, it is nil if the header is absent or "*".
+// The request is aborted with 400 if the header lists several tags.
+// Weak tags and tags of no version never match, so they fail the precondition.
+func ifMatchVersion(c *gin.Context) (*int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, true
+	}
+
+	if strings.Contains(header, ",") {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": "If-Match must be a single entity tag",
+		})
+		return nil, false
+	}
+
+	version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(header, `"`), `"`))
+	if err != nil || header != companyETag(version) {
+		abortVersionMismatch(c)
+		return nil, false
+	}
+	return &version, true
+}
+
+func abortVersionMismatch(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{
+		"message": "Company version mismatch",
+	})
+}
@@ -24,9 +24,17 @@ func (s *Server) CreateCompany(c *gin.Context) {
 
 	err := s.svc.CreateCompany(c.Request.Context(), req.ToDomain())
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"message": err.Error(),
-		})
+		if errors.Is(err, models.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		if abortDBError(c, err) {
+			return
+		}
+
+		s.abortInternalError(c, err)
 		return
 	}
 
@@ -52,7 +60,14 @@ func (s *Server) UpdateCompany(c *gin.Context) {
 		return
 	}
 
-	err := s.svc.UpdateCompany(c.Request.Context(), req.ToDomain(uid))
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	patch := req.ToDomain(uid)
+	patch.Version = version
+
+	err := s.svc.UpdateCompany(c.Request.Context(), patch)
 	if err != nil {
 		if errors.Is(err, models.ErrCompanyNotFound) {
 			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
@@ -60,10 +75,21 @@ func (s *Server) UpdateCompany(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, models.ErrVersionMismatch) {
+			abortVersionMismatch(c)
+			return
+		}
+		if errors.Is(err, models.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		if abortDBError(c, err) {
+			return
+		}
 
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"message": err.Error(),
-		})
+		s.abortInternalError(c, err)
 		return
 	}
 
@@ -81,7 +107,12 @@ func (s *Server) DeleteCompany(c *gin.Context) {
 	}
 	s.log.With("uuid", uid).Debug("Server.DeleteCompany")
 
-	err := s.svc.DeleteCompany(c.Request.Context(), uid)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	err := s.svc.DeleteCompany(c.Request.Context(), uid, version)
 	if err != nil {
 		if errors.Is(err, models.ErrCompanyNotFound) {
 			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
@@ -89,14 +120,61 @@ func (s *Server) DeleteCompany(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, models.ErrVersionMismatch) {
+			abortVersionMismatch(c)
+			return
+		}
+		if errors.Is(err, models.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		if abortDBError(c, err) {
+			return
+		}
+
+		s.abortInternalError(c, err)
+		return
+	}
 
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+	c.JSON(http.StatusOK, nil)
+}
+
+func (s *Server) RestoreCompany(c *gin.Context) {
+	uid := strings.ToLower(c.Param("uuid"))
+
+	if _, err := uuid.Parse(uid); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"message": err.Error(),
 		})
 		return
 	}
+	s.log.With("uuid", uid).Debug("Server.RestoreCompany")
 
-	c.JSON(http.StatusOK, nil)
+	company, err := s.svc.RestoreCompany(c.Request.Context(), uid)
+	if err != nil {
+		if errors.Is(err, models.ErrCompanyNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"message": "Deleted company not found",
+			})
+			return
+		}
+		if errors.Is(err, models.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		if abortDBError(c, err) {
+			return
+		}
+
+		s.abortInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, company)
 }
 
 func (s *Server) GetCompany(c *gin.Context) {
@@ -108,15 +186,27 @@ func (s *Server) GetCompany(c *gin.Context) {
 		})
 		return
 	}
-	s.log.With("uuid", uid).Debug("Server.GetCompany")
 
-	company, err := s.svc.GetCompany(c.Request.Context(), uid)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+	var req requests.GetCompany
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"message": err.Error(),
 		})
 		return
 	}
+	s.log.With("uuid", uid, "as_of", req.AsOf).Debug("Server.GetCompany")
+
+	var company *models.Company
+	var err error
+	if req.AsOf != nil {
+		company, err = s.svc.GetCompanyAsOf(c.Request.Context(), uid, *req.AsOf)
+	} else {
+		company, err = s.svc.GetCompany(c.Request.Context(), uid)
+	}
+	if err != nil {
+		s.abortInternalError(c, err)
+		return
+	}
 
 	if company == nil {
 		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
@@ -125,5 +215,50 @@ func (s *Server) GetCompany(c *gin.Context) {
 		return
 	}
 
+	etag := companyETag(company.Version)
+	c.Header("ETag", etag)
+	if notModified(c, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
 	c.JSON(http.StatusOK, company)
 }
+
+func (s *Server) ListCompanies(c *gin.Context) {
+	var req requests.ListCompanies
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	filter := req.ToDomain()
+	s.log.With("filter", filter).Debug("Server.ListCompanies")
+
+	list, err := s.svc.ListCompanies(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		s.abortInternalError(c, err)
+		return
+	}
+
+	resp := gin.H{
+		"companies":   list.Companies,
+		"limit":       filter.Limit,
+		"next_cursor": list.NextCursor,
+		"prev_cursor": list.PrevCursor,
+	}
+	if list.Total != nil {
+		resp["total"] = *list.Total
+		resp["offset"] = filter.Offset
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ezhdanovskiy/companies/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz reports that the process is alive.
+func (s *Server) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": health.StatusOK,
+	})
+}
+
+// Readyz reports whether the service is able to serve traffic with a breakdown by dependency.
+func (s *Server) Readyz(c *gin.Context) {
+	report := s.health.Check(c.Request.Context())
+	if report.Status != health.StatusOK {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,14 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS serves the public keys of issued access tokens.
+func (s *Server) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, auth.JWKS())
+}
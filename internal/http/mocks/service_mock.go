@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/ezhdanovskiy/companies/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,65 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// AuthenticateAPIKey mocks base method.
+func (m *MockService) AuthenticateAPIKey(arg0 context.Context, arg1 string) (*models.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(*models.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey.
+func (mr *MockServiceMockRecorder) AuthenticateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockService)(nil).AuthenticateAPIKey), arg0, arg1)
+}
+
+// BeginIdempotentRequest mocks base method.
+func (m *MockService) BeginIdempotentRequest(arg0 context.Context, arg1, arg2 string) (*models.IdempotentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginIdempotentRequest", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.IdempotentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginIdempotentRequest indicates an expected call of BeginIdempotentRequest.
+func (mr *MockServiceMockRecorder) BeginIdempotentRequest(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginIdempotentRequest", reflect.TypeOf((*MockService)(nil).BeginIdempotentRequest), arg0, arg1, arg2)
+}
+
+// CompleteIdempotentRequest mocks base method.
+func (m *MockService) CompleteIdempotentRequest(arg0 context.Context, arg1 *models.IdempotentRequest, arg2 *models.IdempotentResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotentRequest", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotentRequest indicates an expected call of CompleteIdempotentRequest.
+func (mr *MockServiceMockRecorder) CompleteIdempotentRequest(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotentRequest", reflect.TypeOf((*MockService)(nil).CompleteIdempotentRequest), arg0, arg1, arg2)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockService) CreateAPIKey(arg0 context.Context, arg1 *models.NewAPIKey) (*models.CreatedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(*models.CreatedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockServiceMockRecorder) CreateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockService)(nil).CreateAPIKey), arg0, arg1)
+}
+
 // CreateCompany mocks base method.
 func (m *MockService) CreateCompany(arg0 context.Context, arg1 *models.Company) error {
 	m.ctrl.T.Helper()
@@ -49,18 +109,61 @@ func (mr *MockServiceMockRecorder) CreateCompany(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCompany", reflect.TypeOf((*MockService)(nil).CreateCompany), arg0, arg1)
 }
 
+// CreateUser mocks base method.
+func (m *MockService) CreateUser(arg0 context.Context, arg1 *models.NewUser) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockServiceMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockService)(nil).CreateUser), arg0, arg1)
+}
+
 // DeleteCompany mocks base method.
-func (m *MockService) DeleteCompany(arg0 context.Context, arg1 string) error {
+func (m *MockService) DeleteCompany(arg0 context.Context, arg1 string, arg2 *int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCompany", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteCompany", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteCompany indicates an expected call of DeleteCompany.
-func (mr *MockServiceMockRecorder) DeleteCompany(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) DeleteCompany(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompany", reflect.TypeOf((*MockService)(nil).DeleteCompany), arg0, arg1, arg2)
+}
+
+// DisableUser mocks base method.
+func (m *MockService) DisableUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableUser indicates an expected call of DisableUser.
+func (mr *MockServiceMockRecorder) DisableUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUser", reflect.TypeOf((*MockService)(nil).DisableUser), arg0, arg1)
+}
+
+// EnableUser mocks base method.
+func (m *MockService) EnableUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableUser indicates an expected call of EnableUser.
+func (mr *MockServiceMockRecorder) EnableUser(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompany", reflect.TypeOf((*MockService)(nil).DeleteCompany), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUser", reflect.TypeOf((*MockService)(nil).EnableUser), arg0, arg1)
 }
 
 // GetCompany mocks base method.
@@ -78,6 +181,213 @@ func (mr *MockServiceMockRecorder) GetCompany(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompany", reflect.TypeOf((*MockService)(nil).GetCompany), arg0, arg1)
 }
 
+// GetCompanyAsOf mocks base method.
+func (m *MockService) GetCompanyAsOf(arg0 context.Context, arg1 string, arg2 time.Time) (*models.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCompanyAsOf", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCompanyAsOf indicates an expected call of GetCompanyAsOf.
+func (mr *MockServiceMockRecorder) GetCompanyAsOf(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyAsOf", reflect.TypeOf((*MockService)(nil).GetCompanyAsOf), arg0, arg1, arg2)
+}
+
+// GetCompanyAudit mocks base method.
+func (m *MockService) GetCompanyAudit(arg0 context.Context, arg1 *models.AuditFilter) ([]*models.AuditRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCompanyAudit", arg0, arg1)
+	ret0, _ := ret[0].([]*models.AuditRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCompanyAudit indicates an expected call of GetCompanyAudit.
+func (mr *MockServiceMockRecorder) GetCompanyAudit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyAudit", reflect.TypeOf((*MockService)(nil).GetCompanyAudit), arg0, arg1)
+}
+
+// GetCompanyVersion mocks base method.
+func (m *MockService) GetCompanyVersion(arg0 context.Context, arg1 string, arg2 int) (*models.CompanyVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCompanyVersion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.CompanyVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCompanyVersion indicates an expected call of GetCompanyVersion.
+func (mr *MockServiceMockRecorder) GetCompanyVersion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyVersion", reflect.TypeOf((*MockService)(nil).GetCompanyVersion), arg0, arg1, arg2)
+}
+
+// IsTokenRevoked mocks base method.
+func (m *MockService) IsTokenRevoked(arg0 context.Context, arg1, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTokenRevoked", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTokenRevoked indicates an expected call of IsTokenRevoked.
+func (mr *MockServiceMockRecorder) IsTokenRevoked(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenRevoked", reflect.TypeOf((*MockService)(nil).IsTokenRevoked), arg0, arg1, arg2)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockService) ListAPIKeys(arg0 context.Context) ([]*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0)
+	ret0, _ := ret[0].([]*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockServiceMockRecorder) ListAPIKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockService)(nil).ListAPIKeys), arg0)
+}
+
+// ListCompanies mocks base method.
+func (m *MockService) ListCompanies(arg0 context.Context, arg1 *models.CompanyFilter) (*models.CompanyList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCompanies", arg0, arg1)
+	ret0, _ := ret[0].(*models.CompanyList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCompanies indicates an expected call of ListCompanies.
+func (mr *MockServiceMockRecorder) ListCompanies(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanies", reflect.TypeOf((*MockService)(nil).ListCompanies), arg0, arg1)
+}
+
+// ListCompanyVersions mocks base method.
+func (m *MockService) ListCompanyVersions(arg0 context.Context, arg1 *models.VersionFilter) ([]*models.CompanyVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCompanyVersions", arg0, arg1)
+	ret0, _ := ret[0].([]*models.CompanyVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCompanyVersions indicates an expected call of ListCompanyVersions.
+func (mr *MockServiceMockRecorder) ListCompanyVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanyVersions", reflect.TypeOf((*MockService)(nil).ListCompanyVersions), arg0, arg1)
+}
+
+// Login mocks base method.
+func (m *MockService) Login(arg0 context.Context, arg1, arg2 string) (*models.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockServiceMockRecorder) Login(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockService)(nil).Login), arg0, arg1, arg2)
+}
+
+// Logout mocks base method.
+func (m *MockService) Logout(arg0 context.Context, arg1 *models.AccessToken, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockServiceMockRecorder) Logout(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockService)(nil).Logout), arg0, arg1, arg2)
+}
+
+// RefreshTokens mocks base method.
+func (m *MockService) RefreshTokens(arg0 context.Context, arg1 string) (*models.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTokens", arg0, arg1)
+	ret0, _ := ret[0].(*models.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshTokens indicates an expected call of RefreshTokens.
+func (mr *MockServiceMockRecorder) RefreshTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTokens", reflect.TypeOf((*MockService)(nil).RefreshTokens), arg0, arg1)
+}
+
+// RestoreCompany mocks base method.
+func (m *MockService) RestoreCompany(arg0 context.Context, arg1 string) (*models.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCompany", arg0, arg1)
+	ret0, _ := ret[0].(*models.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreCompany indicates an expected call of RestoreCompany.
+func (mr *MockServiceMockRecorder) RestoreCompany(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCompany", reflect.TypeOf((*MockService)(nil).RestoreCompany), arg0, arg1)
+}
+
+// RevertCompany mocks base method.
+func (m *MockService) RevertCompany(arg0 context.Context, arg1 string, arg2 int) (*models.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevertCompany", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevertCompany indicates an expected call of RevertCompany.
+func (mr *MockServiceMockRecorder) RevertCompany(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertCompany", reflect.TypeOf((*MockService)(nil).RevertCompany), arg0, arg1, arg2)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockService) RevokeAPIKey(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockServiceMockRecorder) RevokeAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockService)(nil).RevokeAPIKey), arg0, arg1)
+}
+
+// RevokeToken mocks base method.
+func (m *MockService) RevokeToken(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockServiceMockRecorder) RevokeToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockService)(nil).RevokeToken), arg0, arg1)
+}
+
 // UpdateCompany mocks base method.
 func (m *MockService) UpdateCompany(arg0 context.Context, arg1 *models.CompanyPatch) error {
 	m.ctrl.T.Helper()
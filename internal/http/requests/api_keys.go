@@ -0,0 +1,22 @@
+package requests
+
+import (
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+)
+
+// CreateAPIKey creates a key with the scopes, expires_at must be in the future if set.
+type CreateAPIKey struct {
+	Name      string     `json:"name" binding:"required,max=64"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,oneof=companies:read companies:write companies:delete users:manage"` //nolint:lll
+	ExpiresAt *time.Time `json:"expires_at" binding:"omitempty,gt"`
+}
+
+func (c *CreateAPIKey) ToDomain() *models.NewAPIKey {
+	return &models.NewAPIKey{
+		Name:      c.Name,
+		Scopes:    c.Scopes,
+		ExpiresAt: c.ExpiresAt,
+	}
+}
@@ -0,0 +1,22 @@
+package requests
+
+import "github.com/ezhdanovskiy/companies/internal/models"
+
+type ListAudit struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+func (c *ListAudit) ToDomain(companyID string) *models.AuditFilter {
+	filter := &models.AuditFilter{
+		CompanyID: companyID,
+		Limit:     c.Limit,
+		Offset:    c.Offset,
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultListLimit
+	}
+
+	return filter
+}
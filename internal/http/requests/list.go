@@ -0,0 +1,45 @@
+package requests
+
+import "github.com/ezhdanovskiy/companies/internal/models"
+
+const (
+	defaultListLimit = 20
+	defaultListSort  = "name"
+)
+
+type ListCompanies struct {
+	Type         *string `form:"type" binding:"omitempty,oneof=Corporations NonProfit Cooperative 'Sole Proprietorship'"`
+	Registered   *bool   `form:"registered" binding:"omitempty"`
+	EmployeesMin *int    `form:"employees_min" binding:"omitempty,min=0"`
+	EmployeesMax *int    `form:"employees_max" binding:"omitempty,min=0"`
+	NamePrefix   string  `form:"name_prefix" binding:"omitempty,max=15"`
+	Sort         string  `form:"sort" binding:"omitempty,oneof=id name employees_amount registered type created_at updated_at"`
+	Order        string  `form:"order" binding:"omitempty,oneof=asc desc"`
+	Limit        int     `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset       int     `form:"offset" binding:"omitempty,min=0"`
+	Cursor       string  `form:"cursor" binding:"omitempty,max=1024"`
+}
+
+func (c *ListCompanies) ToDomain() *models.CompanyFilter {
+	filter := &models.CompanyFilter{
+		Type:         c.Type,
+		Registered:   c.Registered,
+		EmployeesMin: c.EmployeesMin,
+		EmployeesMax: c.EmployeesMax,
+		NamePrefix:   c.NamePrefix,
+		SortBy:       c.Sort,
+		SortDesc:     c.Order == "desc",
+		Limit:        c.Limit,
+		Offset:       c.Offset,
+		Cursor:       c.Cursor,
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = defaultListSort
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultListLimit
+	}
+
+	return filter
+}
@@ -0,0 +1,36 @@
+package requests
+
+import "github.com/ezhdanovskiy/companies/internal/models"
+
+type Login struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshTokens struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type Logout struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RevokeToken struct {
+	JTI string `json:"jti" binding:"required,max=64"`
+}
+
+type CreateUser struct {
+	Username string   `json:"username" binding:"required,alphanum,max=64"`
+	Email    string   `json:"email" binding:"required,email,max=255"`
+	Password string   `json:"password" binding:"required,min=8,max=72"` // bcrypt uses only 72 bytes
+	Roles    []string `json:"roles" binding:"required,min=1,dive,oneof=viewer editor manager admin"`
+}
+
+func (c *CreateUser) ToDomain() *models.NewUser {
+	return &models.NewUser{
+		Username: c.Username,
+		Email:    c.Email,
+		Password: c.Password,
+		Roles:    c.Roles,
+	}
+}
@@ -0,0 +1,31 @@
+package requests
+
+import (
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+)
+
+// GetCompany reads the current state of a company or its state at as_of, an RFC 3339 timestamp.
+type GetCompany struct {
+	AsOf *time.Time `form:"as_of" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type ListVersions struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+func (c *ListVersions) ToDomain(companyID string) *models.VersionFilter {
+	filter := &models.VersionFilter{
+		CompanyID: companyID,
+		Limit:     c.Limit,
+		Offset:    c.Offset,
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultListLimit
+	}
+
+	return filter
+}
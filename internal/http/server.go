@@ -3,42 +3,80 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/health"
 	"github.com/ezhdanovskiy/companies/internal/middlewares"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.uber.org/zap"
 )
 
 type Server struct {
 	log        *zap.SugaredLogger
-	httpPort   int
 	httpServer *http.Server
 	svc        Service
+	health     *health.Checker
 }
 
-func NewServer(logger *zap.SugaredLogger, httpPort int, svc Service) *Server {
-	return &Server{
-		log:      logger,
-		httpPort: httpPort,
-		svc:      svc,
+// NewServer creates the server, it serves HTTPS if tlsConfig is not nil. serviceName is reported in the server spans.
+// Forwarding headers such as X-Forwarded-For are honored only from trustedProxies, IP addresses or CIDRs.
+func NewServer(
+	logger *zap.SugaredLogger, httpPort int, serviceName string, svc Service, checker *health.Checker, tlsConfig *tls.Config,
+	trustedProxies []string,
+) (*Server, error) {
+	s := &Server{
+		log:    logger,
+		svc:    svc,
+		health: checker,
 	}
-}
 
-func (s *Server) Run() error {
 	router := gin.Default()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("set trusted proxies: %w", err)
+	}
+	router.Use(
+		otelgin.Middleware(serviceName, otelgin.WithFilter(isAPIRequest)),
+		middlewares.RequestID(),
+		middlewares.ClientIP(),
+		middlewares.Metrics(),
+	)
+	router.GET("/healthz", s.Healthz)
+	router.GET("/readyz", s.Readyz)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.GET("/.well-known/jwks.json", s.JWKS)
 	apiV1 := router.Group("/api/v1")
 	s.SetAPIV1Routes(apiV1)
 
 	s.httpServer = &http.Server{
-		Addr:              fmt.Sprintf(":%d", s.httpPort),
+		Addr:              fmt.Sprintf(":%d", httpPort),
 		Handler:           router,
 		ReadHeaderTimeout: 3 * time.Second,
+		TLSConfig:         tlsConfig,
 	}
 
-	err := s.httpServer.ListenAndServe()
+	return s, nil
+}
+
+// isAPIRequest excludes probes and metrics scrapes from tracing.
+func isAPIRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/api/")
+}
+
+func (s *Server) Run() error {
+	var err error
+	if s.httpServer.TLSConfig != nil {
+		// The certificate is provided by the TLS config.
+		err = s.httpServer.ListenAndServeTLS("", "")
+	} else {
+		err = s.httpServer.ListenAndServe()
+	}
 	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("start http server: %w", err)
 	}
@@ -47,15 +85,37 @@ func (s *Server) Run() error {
 }
 
 func (s *Server) SetAPIV1Routes(rg *gin.RouterGroup) {
+	rg.GET("/companies", s.ListCompanies)
 	rg.GET("/companies/:uuid", s.GetCompany)
-	secured := rg.Group("/secured").Use(middlewares.Auth())
-	secured.POST("/companies", s.CreateCompany)
-	secured.PATCH("/companies/:uuid", s.UpdateCompany)
-	secured.DELETE("/companies/:uuid", s.DeleteCompany)
+	rg.GET("/companies/:uuid/versions", middlewares.Auth(s.svc), middlewares.RequireScopes(auth.ScopeCompaniesRead), s.ListCompanyVersions)
+	rg.GET("/companies/:uuid/versions/:n", middlewares.Auth(s.svc), middlewares.RequireScopes(auth.ScopeCompaniesRead), s.GetCompanyVersion)
+	rg.GET("/companies/:uuid/audit", middlewares.Auth(s.svc), middlewares.RequireScopes(auth.ScopeCompaniesRead), s.GetCompanyAudit)
+	// Idempotency follows the scope check, so rejected requests don't take keys and their answers aren't replayed.
+	idempotency := middlewares.Idempotency(s.svc)
+	secured := rg.Group("/secured").Use(middlewares.Auth(s.svc))
+	secured.POST("/companies", middlewares.RequireScopes(auth.ScopeCompaniesWrite), idempotency, s.CreateCompany)
+	secured.PATCH("/companies/:uuid", middlewares.RequireScopes(auth.ScopeCompaniesWrite), idempotency, s.UpdateCompany)
+	secured.DELETE("/companies/:uuid", middlewares.RequireScopes(auth.ScopeCompaniesDelete), idempotency, s.DeleteCompany)
+	secured.POST("/companies/:uuid/restore", middlewares.RequireScopes(auth.ScopeCompaniesDelete), idempotency, s.RestoreCompany)
+	secured.POST("/companies/:uuid/versions/:n/restore", middlewares.RequireScopes(auth.ScopeCompaniesWrite), idempotency,
+		s.RevertCompany)
+
+	rg.POST("/auth/login", s.Login)
+	rg.POST("/auth/refresh", s.RefreshTokens)
+	rg.POST("/auth/logout", middlewares.Auth(s.svc), s.Logout)
+	admin := rg.Group("/admin").Use(middlewares.Auth(s.svc), middlewares.RequireScopes(auth.ScopeUsersManage))
+	admin.POST("/users", s.CreateUser)
+	admin.POST("/users/:username/disable", s.DisableUser)
+	admin.POST("/users/:username/enable", s.EnableUser)
+	admin.POST("/tokens/revoke", s.RevokeToken)
+	admin.POST("/api-keys", s.CreateAPIKey)
+	admin.GET("/api-keys", s.ListAPIKeys)
+	admin.DELETE("/api-keys/:id", s.RevokeAPIKey)
 }
 
-func (s *Server) Shutdown() {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+// Shutdown stops accepting new connections and waits up to timeout for in-flight requests to complete.
+func (s *Server) Shutdown(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	if err := s.httpServer.Shutdown(ctx); err != nil {
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ezhdanovskiy/companies/internal/health"
+	"github.com/ezhdanovskiy/companies/internal/http/mocks"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewServer_ClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		want           string
+	}{
+		{name: "spoofed header", trustedProxies: nil, want: "192.0.2.1"},
+		{name: "trusted proxy", trustedProxies: []string{"192.0.2.0/24"}, want: "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, tt.trustedProxies)
+			var clientIP string
+			router.GET("/client-ip", func(c *gin.Context) {
+				clientIP = requestctx.ClientIP(c.Request.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/client-ip", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, clientIP)
+		})
+	}
+}
+
+func TestNewServer_InvalidTrustedProxies(t *testing.T) {
+	_, err := NewServer(zap.NewNop().Sugar(), 0, "companies", nil, health.NewChecker(), nil, []string{"not-ip"})
+	assert.Error(t, err)
+}
+
+func newTestRouter(t *testing.T, trustedProxies []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	svc := mocks.NewMockService(gomock.NewController(t))
+	s, err := NewServer(zap.NewNop().Sugar(), 0, "companies", svc, health.NewChecker(), nil, trustedProxies)
+	require.NoError(t, err)
+	return s.httpServer.Handler.(*gin.Engine)
+}
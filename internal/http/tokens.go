@@ -0,0 +1,118 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/http/requests"
+	"github.com/ezhdanovskiy/companies/internal/middlewares"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) RefreshTokens(c *gin.Context) {
+	s.log.Debug("Server.RefreshTokens")
+
+	var req requests.RefreshTokens
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	tokens, err := s.svc.RefreshTokens(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidRefreshToken), errors.Is(err, models.ErrRefreshTokenReused):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"message": err.Error(),
+			})
+		case errors.Is(err, models.ErrUserDisabled):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+		default:
+			s.abortInternalError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokensResponse(tokens))
+}
+
+func (s *Server) Logout(c *gin.Context) {
+	claims, ok := c.Value(middlewares.ClaimsKey).(*auth.JWTClaim)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": "logout requires an access token",
+		})
+		return
+	}
+	s.log.With("jti", claims.Id).Debug("Server.Logout")
+
+	var req requests.Logout
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	access := &models.AccessToken{
+		ID:        claims.Id,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}
+	err := s.svc.Logout(c.Request.Context(), access, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidRefreshToken) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		s.abortInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+func (s *Server) RevokeToken(c *gin.Context) {
+	var req requests.RevokeToken
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	s.log.With("jti", req.JTI).Debug("Server.RevokeToken")
+
+	if err := s.svc.RevokeToken(c.Request.Context(), req.JTI); err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		s.abortInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+func tokensResponse(tokens *models.Tokens) gin.H {
+	return gin.H{
+		"access_token":       tokens.Access.Token,
+		"token_type":         "Bearer",
+		"expires_in":         int(time.Until(tokens.Access.ExpiresAt).Round(time.Second).Seconds()),
+		"refresh_token":      tokens.RefreshToken,
+		"refresh_expires_in": int(time.Until(tokens.RefreshExpiresAt).Round(time.Second).Seconds()),
+	}
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ezhdanovskiy/companies/internal/http/requests"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) Login(c *gin.Context) {
+	var req requests.Login
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	s.log.With("username", req.Username).Debug("Server.Login")
+
+	tokens, err := s.svc.Login(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidCredentials):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"message": err.Error(),
+			})
+		case errors.Is(err, models.ErrUserDisabled):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+		default:
+			s.abortInternalError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokensResponse(tokens))
+}
+
+func (s *Server) CreateUser(c *gin.Context) {
+	s.log.Debug("Server.CreateUser")
+
+	var req requests.CreateUser
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	user, err := s.svc.CreateUser(c.Request.Context(), req.ToDomain())
+	if err != nil {
+		if errors.Is(err, models.ErrUserAlreadyExists) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, models.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		if abortDBError(c, err) {
+			return
+		}
+
+		s.abortInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":       user.ID,
+		"username": user.Username,
+		"email":    user.Email,
+		"roles":    user.Roles,
+	})
+}
+
+func (s *Server) DisableUser(c *gin.Context) {
+	username := c.Param("username")
+	s.log.With("username", username).Debug("Server.DisableUser")
+	s.respondUserUpdate(c, s.svc.DisableUser(c.Request.Context(), username))
+}
+
+func (s *Server) EnableUser(c *gin.Context) {
+	username := c.Param("username")
+	s.log.With("username", username).Debug("Server.EnableUser")
+	s.respondUserUpdate(c, s.svc.EnableUser(c.Request.Context(), username))
+}
+
+func (s *Server) respondUserUpdate(c *gin.Context, err error) {
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"message": "User not found",
+			})
+			return
+		}
+		if errors.Is(err, models.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		s.abortInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
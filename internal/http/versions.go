@@ -0,0 +1,150 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ezhdanovskiy/companies/internal/http/requests"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (s *Server) ListCompanyVersions(c *gin.Context) {
+	uid := strings.ToLower(c.Param("uuid"))
+
+	if _, err := uuid.Parse(uid); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	var req requests.ListVersions
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	filter := req.ToDomain(uid)
+	s.log.With("filter", filter).Debug("Server.ListCompanyVersions")
+
+	versions, err := s.svc.ListCompanyVersions(c.Request.Context(), filter)
+	if err != nil {
+		s.abortInternalError(c, err)
+		return
+	}
+
+	items := make([]gin.H, 0, len(versions))
+	for _, version := range versions {
+		items = append(items, versionResponse(version))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"versions": items,
+		"limit":    filter.Limit,
+		"offset":   filter.Offset,
+	})
+}
+
+func (s *Server) GetCompanyVersion(c *gin.Context) {
+	uid := strings.ToLower(c.Param("uuid"))
+
+	if _, err := uuid.Parse(uid); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	n, ok := versionParam(c)
+	if !ok {
+		return
+	}
+	s.log.With("uuid", uid, "version", n).Debug("Server.GetCompanyVersion")
+
+	version, err := s.svc.GetCompanyVersion(c.Request.Context(), uid, n)
+	if err != nil {
+		s.abortInternalError(c, err)
+		return
+	}
+
+	if version == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"message": "Version not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, versionResponse(version))
+}
+
+func (s *Server) RevertCompany(c *gin.Context) {
+	uid := strings.ToLower(c.Param("uuid"))
+
+	if _, err := uuid.Parse(uid); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	n, ok := versionParam(c)
+	if !ok {
+		return
+	}
+	s.log.With("uuid", uid, "version", n).Debug("Server.RevertCompany")
+
+	company, err := s.svc.RevertCompany(c.Request.Context(), uid, n)
+	if err != nil {
+		if errors.Is(err, models.ErrCompanyNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"message": "Company not found",
+			})
+			return
+		}
+		if errors.Is(err, models.ErrVersionNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"message": "Version not found",
+			})
+			return
+		}
+		if errors.Is(err, models.ErrForbidden) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		if abortDBError(c, err) {
+			return
+		}
+
+		s.abortInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, company)
+}
+
+// versionParam parses the version number from the path, the request is aborted if it isn't a positive integer.
+func versionParam(c *gin.Context) (int, bool) {
+	n, err := strconv.Atoi(c.Param("n"))
+	if err != nil || n < 1 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": "version must be a positive integer",
+		})
+		return 0, false
+	}
+	return n, true
+}
+
+func versionResponse(version *models.CompanyVersion) gin.H {
+	return gin.H{
+		"version":    version.Version,
+		"company":    version.Company,
+		"deleted":    version.Deleted,
+		"created_at": version.CreatedAt,
+	}
+}
@@ -0,0 +1,230 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+)
+
+// ErrPoisonMessage marks handler errors of messages that will never be processed successfully.
+// Such messages are sent to the dead-letter topic right away.
+var ErrPoisonMessage = errors.New("poison message")
+
+// Headers added to messages sent to the dead-letter topic.
+const (
+	HeaderDeadLetterError     = "dlq-error"
+	HeaderDeadLetterTopic     = "dlq-topic"
+	HeaderDeadLetterPartition = "dlq-partition"
+	HeaderDeadLetterOffset    = "dlq-offset"
+)
+
+// maxFailureBackoff limits the delay between retries of failed fetches, commits and dead-letter writes.
+const maxFailureBackoff = 30 * time.Second
+
+// Handler processes a consumed message.
+type Handler func(ctx context.Context, m *Message) error
+
+type ConsumerConfig struct {
+	Brokers         []string
+	Topic           string
+	GroupID         string
+	DeadLetterTopic string
+	MaxRetries      int
+	RetryBackoff    time.Duration
+}
+
+// Consumer reads messages of a consumer group and commits their offsets only after they are handled
+// or sent to the dead-letter topic.
+type Consumer struct {
+	log     *zap.SugaredLogger
+	cfg     ConsumerConfig
+	reader  *kafka.Reader
+	dlq     *kafka.Writer
+	handler Handler
+
+	mu      sync.Mutex
+	lastErr error // failure of Kafka being retried, nil when the consumer works
+}
+
+func NewConsumer(logger *zap.SugaredLogger, cfg *ConsumerConfig, handler Handler) *Consumer {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        cfg.Brokers,
+		Topic:          cfg.Topic,
+		GroupID:        cfg.GroupID,
+		CommitInterval: 0, // commit synchronously
+		Logger:         kafka.LoggerFunc(zap.S().Debugf),
+		ErrorLogger:    kafka.LoggerFunc(zap.S().Errorf),
+	})
+
+	dlq := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.DeadLetterTopic,
+		RequiredAcks: kafka.RequireAll,
+		Balancer:     &kafka.Murmur2Balancer{},
+		Logger:       kafka.LoggerFunc(zap.S().Debugf),
+		ErrorLogger:  kafka.LoggerFunc(zap.S().Errorf),
+	}
+
+	return &Consumer{
+		log:     logger,
+		cfg:     *cfg,
+		reader:  reader,
+		dlq:     dlq,
+		handler: handler,
+	}
+}
+
+// Run consumes messages until ctx is canceled. The message being handled at that moment is finished first.
+// Failures of Kafka are retried with backoff, meanwhile Ping reports them.
+func (c *Consumer) Run(ctx context.Context) {
+	defer c.close()
+
+	for {
+		var msg kafka.Message
+		err := c.retry(ctx, "fetch message", func() (err error) {
+			msg, err = c.reader.FetchMessage(ctx)
+			return err
+		})
+		if err != nil {
+			return
+		}
+
+		// The handler gets its own context to not be interrupted by shutdown in the middle of a message.
+		if err := c.process(ctx, context.Background(), msg); err != nil {
+			return
+		}
+
+		err = c.retry(ctx, "commit message", func() error {
+			return c.reader.CommitMessages(context.Background(), msg)
+		})
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Ping returns the failure of Kafka the consumer is retrying, it is nil while messages are consumed.
+func (c *Consumer) Ping(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastErr
+}
+
+// retry calls fn until it succeeds or ctx is canceled, the delay between attempts doubles up to maxFailureBackoff.
+// It returns an error only if ctx is canceled.
+func (c *Consumer) retry(ctx context.Context, op string, fn func() error) error {
+	backoff := c.cfg.RetryBackoff
+	for {
+		err := fn()
+		if err == nil {
+			c.setLastErr(nil)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err = fmt.Errorf("%s: %w", op, err)
+		c.setLastErr(err)
+		c.log.With("error", err, "backoff", backoff).Warn("Kafka consumer failed, retrying")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff <= 0 || backoff > maxFailureBackoff {
+			backoff = maxFailureBackoff
+		}
+	}
+}
+
+func (c *Consumer) setLastErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastErr = err
+}
+
+// process handles the message retrying transient errors. Messages that still fail are sent to the dead-letter topic.
+// It returns an error only if ctx is canceled before the message is handled or sent.
+func (c *Consumer) process(ctx, handlerCtx context.Context, msg kafka.Message) error {
+	log := c.log.With("topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+
+	m := toMessage(&msg)
+	handlerCtx, span := startConsumeSpan(handlerCtx, msg.Topic, m)
+	defer span.End()
+
+	for attempt := 0; ; attempt++ {
+		err := c.handler(handlerCtx, m)
+		if err == nil {
+			log.Debug("Message handled")
+			return nil
+		}
+
+		if errors.Is(err, ErrPoisonMessage) || attempt >= c.cfg.MaxRetries {
+			log.With("error", err, "attempts", attempt+1).Warn("Sending message to dead-letter topic")
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			dead := deadLetterMessage(&msg, err)
+			return c.retry(ctx, "write to dead-letter topic", func() error {
+				return c.dlq.WriteMessages(handlerCtx, dead)
+			})
+		}
+
+		log.With("error", err, "attempt", attempt+1).Warn("Failed to handle message, retrying")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.cfg.RetryBackoff):
+		}
+	}
+}
+
+func (c *Consumer) close() {
+	if err := c.reader.Close(); err != nil {
+		c.log.With("error", err).Error("Failed to close kafka reader")
+	}
+	if err := c.dlq.Close(); err != nil {
+		c.log.With("error", err).Error("Failed to close dead-letter writer")
+	}
+}
+
+func toMessage(msg *kafka.Message) *Message {
+	headers := make(Headers, len(msg.Headers))
+	for _, h := range msg.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+
+	return &Message{
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Headers:   headers,
+		Key:       string(msg.Key),
+		Body:      msg.Value,
+	}
+}
+
+func deadLetterMessage(msg *kafka.Message, reason error) kafka.Message {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+4) //nolint:gomnd
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderDeadLetterError, Value: []byte(reason.Error())},
+		kafka.Header{Key: HeaderDeadLetterTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderDeadLetterPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderDeadLetterOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+	)
+
+	return kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
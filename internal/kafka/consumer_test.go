@@ -0,0 +1,81 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestToMessage(t *testing.T) {
+	m := toMessage(&kafka.Message{
+		Partition: 2,
+		Offset:    17,
+		Key:       []byte("key"),
+		Value:     []byte("value"),
+		Headers:   []kafka.Header{{Key: HeaderCorrelationID, Value: []byte("request-id")}},
+	})
+
+	assert.Equal(t, &Message{
+		Partition: 2,
+		Offset:    17,
+		Headers:   Headers{HeaderCorrelationID: "request-id"},
+		Key:       "key",
+		Body:      []byte("value"),
+	}, m)
+}
+
+func TestDeadLetterMessage(t *testing.T) {
+	m := deadLetterMessage(&kafka.Message{
+		Topic:     "commands",
+		Partition: 2,
+		Offset:    17,
+		Key:       []byte("key"),
+		Value:     []byte("value"),
+		Headers:   []kafka.Header{{Key: HeaderCorrelationID, Value: []byte("request-id")}},
+	}, errors.New("handle error"))
+
+	assert.Equal(t, []byte("key"), m.Key)
+	assert.Equal(t, []byte("value"), m.Value)
+	assert.Empty(t, m.Topic)
+	assert.Equal(t, []kafka.Header{
+		{Key: HeaderCorrelationID, Value: []byte("request-id")},
+		{Key: HeaderDeadLetterError, Value: []byte("handle error")},
+		{Key: HeaderDeadLetterTopic, Value: []byte("commands")},
+		{Key: HeaderDeadLetterPartition, Value: []byte("2")},
+		{Key: HeaderDeadLetterOffset, Value: []byte("17")},
+	}, m.Headers)
+}
+
+func TestConsumer_Retry(t *testing.T) {
+	c := &Consumer{log: zap.NewNop().Sugar(), cfg: ConsumerConfig{RetryBackoff: time.Millisecond}}
+
+	attempts := 0
+	err := c.retry(context.Background(), "fetch message", func() error {
+		attempts++
+		if attempts < 3 {
+			assert.Equal(t, attempts > 1, c.Ping(context.Background()) != nil) // The failure is reported meanwhile
+			return errors.New("broker is unavailable")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.NoError(t, c.Ping(context.Background()))
+}
+
+func TestConsumer_Retry_Canceled(t *testing.T) {
+	c := &Consumer{log: zap.NewNop().Sugar(), cfg: ConsumerConfig{RetryBackoff: time.Hour}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := c.retry(ctx, "commit message", func() error {
+		cancel()
+		return errors.New("broker is unavailable")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -2,6 +2,15 @@ package kafka
 
 type Headers map[string]string
 
+// Headers set on every event.
+const (
+	HeaderEventType     = "event-type"
+	HeaderSchemaVersion = "schema-version"
+	HeaderCorrelationID = "correlation-id"
+	HeaderTimestamp     = "timestamp"
+	HeaderContentType   = "content-type"
+)
+
 type Message struct {
 	Partition int
 	Offset    int64
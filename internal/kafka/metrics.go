@@ -0,0 +1,94 @@
+package kafka
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+)
+
+const metricsNamespace = "companies"
+
+// ProducerCollector exposes statistics of the producer's kafka.Writer as Prometheus metrics.
+// kafka.Writer resets its counters on every Stats call, so the collector accumulates them
+// and must be the only consumer of the writer statistics.
+type ProducerCollector struct {
+	producer *AsyncProducer
+	stats    func() kafka.WriterStats
+
+	mu       sync.Mutex
+	writes   float64
+	messages float64
+	bytes    float64
+	errors   float64
+	retries  float64
+
+	writesDesc       *prometheus.Desc
+	messagesDesc     *prometheus.Desc
+	bytesDesc        *prometheus.Desc
+	errorsDesc       *prometheus.Desc
+	retriesDesc      *prometheus.Desc
+	failedDesc       *prometheus.Desc
+	queueDepthDesc   *prometheus.Desc
+	batchSizeDesc    *prometheus.Desc
+	batchSizeMaxDesc *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*ProducerCollector)(nil)
+
+func NewProducerCollector(producer *AsyncProducer) *ProducerCollector {
+	labels := prometheus.Labels{"topic": producer.writer.Topic}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "kafka_producer", name), help, nil, labels)
+	}
+
+	return &ProducerCollector{
+		producer: producer,
+		stats:    producer.writer.Stats,
+
+		writesDesc:       desc("writes_total", "Number of write requests sent to the brokers."),
+		messagesDesc:     desc("messages_total", "Number of messages written."),
+		bytesDesc:        desc("bytes_total", "Number of message bytes written."),
+		errorsDesc:       desc("errors_total", "Number of failed write requests."),
+		retriesDesc:      desc("retries_total", "Number of retried write requests."),
+		failedDesc:       desc("failed_messages_total", "Number of messages not delivered in asynchronous modes."),
+		queueDepthDesc:   desc("queue_depth", "Number of published messages waiting for delivery."),
+		batchSizeDesc:    desc("batch_size", "Average number of messages per batch since the last scrape."),
+		batchSizeMaxDesc: desc("batch_size_max", "Maximum number of messages per batch since the last scrape."),
+	}
+}
+
+func (c *ProducerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.writesDesc
+	ch <- c.messagesDesc
+	ch <- c.bytesDesc
+	ch <- c.errorsDesc
+	ch <- c.retriesDesc
+	ch <- c.failedDesc
+	ch <- c.queueDepthDesc
+	ch <- c.batchSizeDesc
+	ch <- c.batchSizeMaxDesc
+}
+
+func (c *ProducerCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats()
+	c.writes += float64(stats.Writes)
+	c.messages += float64(stats.Messages)
+	c.bytes += float64(stats.Bytes)
+	c.errors += float64(stats.Errors)
+	c.retries += float64(stats.Retries)
+
+	ch <- prometheus.MustNewConstMetric(c.writesDesc, prometheus.CounterValue, c.writes)
+	ch <- prometheus.MustNewConstMetric(c.messagesDesc, prometheus.CounterValue, c.messages)
+	ch <- prometheus.MustNewConstMetric(c.bytesDesc, prometheus.CounterValue, c.bytes)
+	ch <- prometheus.MustNewConstMetric(c.errorsDesc, prometheus.CounterValue, c.errors)
+	ch <- prometheus.MustNewConstMetric(c.retriesDesc, prometheus.CounterValue, c.retries)
+	ch <- prometheus.MustNewConstMetric(c.failedDesc, prometheus.CounterValue, float64(c.producer.Failed()))
+	ch <- prometheus.MustNewConstMetric(c.queueDepthDesc, prometheus.GaugeValue, float64(c.producer.Queued()))
+	ch <- prometheus.MustNewConstMetric(c.batchSizeDesc, prometheus.GaugeValue, float64(stats.BatchSize.Avg))
+	ch <- prometheus.MustNewConstMetric(c.batchSizeMaxDesc, prometheus.GaugeValue, float64(stats.BatchSize.Max))
+}
@@ -0,0 +1,51 @@
+package kafka
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProducerCollector_AccumulatesStats(t *testing.T) {
+	ap, err := NewAsyncProducer(zap.NewNop().Sugar(), &ProducerConfig{Topic: "companies", Delivery: DeliveryLeader})
+	require.NoError(t, err)
+
+	c := NewProducerCollector(ap)
+	c.stats = func() kafka.WriterStats {
+		return kafka.WriterStats{
+			Writes:    1,
+			Messages:  3,
+			Errors:    1,
+			BatchSize: kafka.SummaryStats{Avg: 3, Max: 3},
+		}
+	}
+
+	ap.complete([]kafka.Message{{}, {}}, assert.AnError)
+	assert.Equal(t, 9, testutil.CollectAndCount(c))
+
+	expected := `
+# HELP companies_kafka_producer_messages_total Number of messages written.
+# TYPE companies_kafka_producer_messages_total counter
+companies_kafka_producer_messages_total{topic="companies"} 6
+# HELP companies_kafka_producer_errors_total Number of failed write requests.
+# TYPE companies_kafka_producer_errors_total counter
+companies_kafka_producer_errors_total{topic="companies"} 2
+# HELP companies_kafka_producer_failed_messages_total Number of messages not delivered in asynchronous modes.
+# TYPE companies_kafka_producer_failed_messages_total counter
+companies_kafka_producer_failed_messages_total{topic="companies"} 2
+# HELP companies_kafka_producer_batch_size Average number of messages per batch since the last scrape.
+# TYPE companies_kafka_producer_batch_size gauge
+companies_kafka_producer_batch_size{topic="companies"} 3
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"companies_kafka_producer_messages_total",
+		"companies_kafka_producer_errors_total",
+		"companies_kafka_producer_failed_messages_total",
+		"companies_kafka_producer_batch_size",
+	))
+}
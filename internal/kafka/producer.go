@@ -2,15 +2,34 @@ package kafka
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 	"time"
 
+	"github.com/ezhdanovskiy/companies/internal/tracing"
 	"github.com/pkg/errors"
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 )
 
+// Delivery modes of the producer.
+const (
+	// DeliveryFireAndForget doesn't wait for any acknowledgement, failed deliveries are only recorded.
+	DeliveryFireAndForget = "none"
+	// DeliveryLeader waits for the partition leader asynchronously, failed deliveries are only recorded.
+	DeliveryLeader = "leader"
+	// DeliveryAll waits for all in-sync replicas synchronously, Publish returns delivery errors.
+	DeliveryAll = "all"
+)
+
 type AsyncProducer struct {
-	writer *kafka.Writer
+	log     *zap.SugaredLogger
+	brokers []string
+	writer  *kafka.Writer
+	failed  int64
+	queued  int64
+
+	onFailure func(messages []*Message, err error)
 }
 
 type ProducerConfig struct {
@@ -18,37 +37,143 @@ type ProducerConfig struct {
 	Topic        string
 	BatchSize    int
 	BatchTimeout time.Duration
+	Delivery     string // none | leader | all
+	MaxAttempts  int
+	Compression  string // none | gzip | snappy | lz4 | zstd
+
+	// OnDeliveryFailure is called with messages that were not delivered in asynchronous modes.
+	// It is called from the writer goroutine, so it must not block for long.
+	OnDeliveryFailure func(messages []*Message, err error)
 }
 
-func NewAsyncProducer(cfg *ProducerConfig) *AsyncProducer {
+func NewAsyncProducer(logger *zap.SugaredLogger, cfg *ProducerConfig) (*AsyncProducer, error) {
+	compression, err := compressionCodec(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+
 	writer := &kafka.Writer{
 		Addr:         kafka.TCP(cfg.Brokers...),
 		Topic:        cfg.Topic,
 		BatchSize:    cfg.BatchSize,
 		BatchTimeout: cfg.BatchTimeout,
-		RequiredAcks: kafka.RequireNone,
-		Async:        true,
+		MaxAttempts:  cfg.MaxAttempts,
+		Compression:  compression,
 		Balancer:     &kafka.Murmur2Balancer{},
 		Logger:       kafka.LoggerFunc(zap.S().Debugf),
 		ErrorLogger:  kafka.LoggerFunc(zap.S().Errorf),
 	}
-	return &AsyncProducer{
-		writer: writer,
+
+	switch cfg.Delivery {
+	case DeliveryFireAndForget:
+		writer.RequiredAcks = kafka.RequireNone
+		writer.Async = true
+	case DeliveryLeader:
+		writer.RequiredAcks = kafka.RequireOne
+		writer.Async = true
+	case DeliveryAll, "":
+		writer.RequiredAcks = kafka.RequireAll
+	default:
+		return nil, fmt.Errorf("unknown delivery mode %q", cfg.Delivery)
+	}
+
+	ap := &AsyncProducer{
+		log:       logger,
+		brokers:   cfg.Brokers,
+		writer:    writer,
+		onFailure: cfg.OnDeliveryFailure,
+	}
+	if writer.Async {
+		writer.Completion = ap.complete
+	}
+
+	return ap, nil
+}
+
+func compressionCodec(name string) (kafka.Compression, error) {
+	switch name {
+	case "", "none":
+		return 0, nil
+	case "gzip":
+		return kafka.Gzip, nil
+	case "snappy":
+		return kafka.Snappy, nil
+	case "lz4":
+		return kafka.Lz4, nil
+	case "zstd":
+		return kafka.Zstd, nil
+	}
+	return 0, fmt.Errorf("unknown compression codec %q", name)
+}
+
+// complete is called by the writer in asynchronous modes when a batch is delivered or failed.
+func (ap *AsyncProducer) complete(messages []kafka.Message, err error) {
+	atomic.AddInt64(&ap.queued, -int64(len(messages)))
+	if err == nil {
+		return
+	}
+
+	atomic.AddInt64(&ap.failed, int64(len(messages)))
+	ap.log.With("error", err, "amount", len(messages)).Error("Failed to deliver messages")
+
+	if ap.onFailure != nil {
+		failed := make([]*Message, 0, len(messages))
+		for i := range messages {
+			failed = append(failed, toMessage(&messages[i]))
+		}
+		ap.onFailure(failed, err)
 	}
 }
 
-func (ap *AsyncProducer) Publish(ctx context.Context, messages ...[]byte) error {
+// Synchronous reports whether Publish waits for the brokers to acknowledge messages and returns delivery errors.
+func (ap *AsyncProducer) Synchronous() bool {
+	return !ap.writer.Async
+}
+
+// Failed returns the number of messages that were not delivered in asynchronous modes.
+func (ap *AsyncProducer) Failed() int64 {
+	return atomic.LoadInt64(&ap.failed)
+}
+
+// Queued returns the number of messages accepted by Publish and not yet delivered or failed.
+func (ap *AsyncProducer) Queued() int64 {
+	return atomic.LoadInt64(&ap.queued)
+}
+
+func (ap *AsyncProducer) Publish(ctx context.Context, messages ...*Message) (err error) {
+	ctx, span := startPublishSpan(ctx, ap.writer.Topic, messages)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	mm := make([]kafka.Message, 0, len(messages))
 
-	for i := range messages {
-		message := messages[i]
-		mm = append(mm, kafka.Message{
-			Value: message,
-		})
+	for _, message := range messages {
+		m := kafka.Message{
+			Value: message.Body,
+		}
+		if message.Key != "" {
+			m.Key = []byte(message.Key)
+		}
+
+		headers := make(Headers, len(message.Headers))
+		for k, v := range message.Headers {
+			headers[k] = v
+		}
+		injectTraceContext(ctx, headers)
+
+		m.Headers = make([]kafka.Header, 0, len(headers))
+		for k, v := range headers {
+			m.Headers = append(m.Headers, kafka.Header{Key: k, Value: []byte(v)})
+		}
+		mm = append(mm, m)
 	}
 
 	if len(mm) > 0 {
+		atomic.AddInt64(&ap.queued, int64(len(mm)))
 		err := ap.writer.WriteMessages(ctx, mm...)
+		// In asynchronous modes accepted messages are dequeued by complete.
+		if err != nil || !ap.writer.Async {
+			atomic.AddInt64(&ap.queued, -int64(len(mm)))
+		}
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -57,6 +182,22 @@ func (ap *AsyncProducer) Publish(ctx context.Context, messages ...[]byte) error
 	return nil
 }
 
+// Ping checks that at least one of the brokers is reachable.
+func (ap *AsyncProducer) Ping(ctx context.Context) error {
+	var err error
+	for _, broker := range ap.brokers {
+		var conn *kafka.Conn
+		conn, err = kafka.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			return conn.Close()
+		}
+	}
+	if err == nil {
+		return errors.New("no brokers configured")
+	}
+	return err
+}
+
 func (ap *AsyncProducer) Close() error {
 	return ap.writer.Close()
 }
@@ -0,0 +1,81 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewAsyncProducer_DeliveryModes(t *testing.T) {
+	for _, tc := range []struct {
+		delivery string
+		acks     kafka.RequiredAcks
+		async    bool
+	}{
+		{delivery: DeliveryFireAndForget, acks: kafka.RequireNone, async: true},
+		{delivery: DeliveryLeader, acks: kafka.RequireOne, async: true},
+		{delivery: DeliveryAll, acks: kafka.RequireAll, async: false},
+		{delivery: "", acks: kafka.RequireAll, async: false},
+	} {
+		ap, err := NewAsyncProducer(zap.NewNop().Sugar(), &ProducerConfig{Delivery: tc.delivery})
+		require.NoError(t, err, tc.delivery)
+		assert.Equal(t, tc.acks, ap.writer.RequiredAcks, tc.delivery)
+		assert.Equal(t, tc.async, ap.writer.Async, tc.delivery)
+		assert.Equal(t, tc.async, ap.writer.Completion != nil, tc.delivery)
+		assert.Equal(t, !tc.async, ap.Synchronous(), tc.delivery)
+	}
+}
+
+func TestNewAsyncProducer_UnknownDelivery(t *testing.T) {
+	_, err := NewAsyncProducer(zap.NewNop().Sugar(), &ProducerConfig{Delivery: "exactly-once"})
+	require.Error(t, err)
+}
+
+func TestNewAsyncProducer_Compression(t *testing.T) {
+	for name, codec := range map[string]kafka.Compression{
+		"":       0,
+		"none":   0,
+		"gzip":   kafka.Gzip,
+		"snappy": kafka.Snappy,
+		"lz4":    kafka.Lz4,
+		"zstd":   kafka.Zstd,
+	} {
+		ap, err := NewAsyncProducer(zap.NewNop().Sugar(), &ProducerConfig{Compression: name})
+		require.NoError(t, err, name)
+		assert.Equal(t, codec, ap.writer.Compression, name)
+	}
+
+	_, err := NewAsyncProducer(zap.NewNop().Sugar(), &ProducerConfig{Compression: "brotli"})
+	require.Error(t, err)
+}
+
+func TestAsyncProducer_Complete(t *testing.T) {
+	var failed []*Message
+	ap, err := NewAsyncProducer(zap.NewNop().Sugar(), &ProducerConfig{
+		Delivery: DeliveryLeader,
+		OnDeliveryFailure: func(messages []*Message, err error) {
+			failed = append(failed, messages...)
+		},
+	})
+	require.NoError(t, err)
+
+	messages := []kafka.Message{
+		{Key: []byte("key"), Value: []byte("first"), Headers: []kafka.Header{{Key: HeaderEventType, Value: []byte("created")}}},
+		{Key: []byte("key"), Value: []byte("second")},
+	}
+
+	ap.complete(messages, nil)
+	assert.Zero(t, ap.Failed())
+	assert.Empty(t, failed)
+
+	ap.complete(messages, errors.New("delivery error"))
+	assert.EqualValues(t, 2, ap.Failed())
+	assert.Equal(t, []*Message{
+		{Key: "key", Body: []byte("first"), Headers: Headers{HeaderEventType: "created"}},
+		{Key: "key", Body: []byte("second"), Headers: Headers{}},
+	}, failed)
+}
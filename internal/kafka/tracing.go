@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ezhdanovskiy/companies/internal/kafka"
+
+// Trace context header defined by W3C Trace Context.
+const headerTraceParent = "traceparent"
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// startPublishSpan starts a producer span linked to the trace contexts of the messages.
+func startPublishSpan(ctx context.Context, topic string, messages []*Message) (context.Context, trace.Span) {
+	links := make([]trace.Link, 0, len(messages))
+	for _, m := range messages {
+		sc := trace.SpanContextFromContext(extractTraceContext(context.Background(), m.Headers))
+		if sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+
+	return tracer().Start(ctx, "AsyncProducer.Publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithLinks(links...),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+			attribute.Int("messaging.batch.message_count", len(messages)),
+		),
+	)
+}
+
+// startConsumeSpan starts a consumer span continuing the trace of the message.
+func startConsumeSpan(ctx context.Context, topic string, m *Message) (context.Context, trace.Span) {
+	ctx = extractTraceContext(ctx, m.Headers)
+
+	return tracer().Start(ctx, "Consumer.process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.source.name", topic),
+			attribute.Int("messaging.kafka.source.partition", m.Partition),
+			attribute.Int64("messaging.kafka.message.offset", m.Offset),
+		),
+	)
+}
+
+// injectTraceContext writes the trace context of ctx into headers unless they already carry one,
+// so events keep the trace of the request that produced them.
+func injectTraceContext(ctx context.Context, headers Headers) {
+	if _, ok := headers[headerTraceParent]; ok {
+		return
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+}
+
+func extractTraceContext(ctx context.Context, headers Headers) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
@@ -0,0 +1,29 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInjectTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	publishParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := extractTraceContext(context.Background(), Headers{headerTraceParent: publishParent})
+	assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+
+	headers := Headers{}
+	injectTraceContext(ctx, headers)
+	assert.Equal(t, publishParent, headers[headerTraceParent])
+
+	requestParent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	headers = Headers{headerTraceParent: requestParent}
+	injectTraceContext(ctx, headers)
+	assert.Equal(t, requestParent, headers[headerTraceParent]) // The trace of the request is kept
+}
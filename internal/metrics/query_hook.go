@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// QueryHook records durations of bun queries to DBQueryDuration.
+type QueryHook struct{}
+
+var _ bun.QueryHook = (*QueryHook)(nil)
+
+func (h *QueryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *QueryHook) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	status := StatusOK
+	if event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows) {
+		status = StatusError
+	}
+
+	DBQueryDuration.WithLabelValues(event.Operation(), status).Observe(time.Since(event.StartTime).Seconds())
+}
@@ -1,27 +1,110 @@
 package middlewares
 
 import (
+	"context"
+	"crypto/x509"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
 	"github.com/gin-gonic/gin"
 )
 
-func Auth() gin.HandlerFunc {
+// ClaimsKey is the gin context key of the claims of the validated access token.
+const ClaimsKey = "claims"
+
+// HeaderAPIKey is the request header of API keys, an alternative to access tokens.
+const HeaderAPIKey = "X-API-Key"
+
+// Authenticator checks credentials of requests.
+type Authenticator interface {
+	IsTokenRevoked(ctx context.Context, jti, userID string) (bool, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.Principal, error)
+}
+
+// Auth authenticates the request by the API key, the access token or the verified client certificate
+// and puts the principal into the request context. Claims of the access token are also put into the gin context.
+func Auth(authenticator Authenticator) gin.HandlerFunc {
 	return func(context *gin.Context) {
+		if key := context.GetHeader(HeaderAPIKey); key != "" {
+			principal, err := authenticator.AuthenticateAPIKey(context.Request.Context(), key)
+			if err != nil {
+				if errors.Is(err, models.ErrInvalidAPIKey) {
+					context.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				} else {
+					_ = context.Error(err)
+					context.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check the API key"})
+				}
+				context.Abort()
+				return
+			}
+			context.Request = context.Request.WithContext(
+				requestctx.WithPrincipal(context.Request.Context(), principal))
+			context.Next()
+			return
+		}
+
 		tokenString := strings.TrimPrefix(context.GetHeader("authorization"), "Bearer ")
+		if cert := verifiedClientCert(context.Request); tokenString == "" && cert != nil {
+			context.Request = context.Request.WithContext(
+				requestctx.WithPrincipal(context.Request.Context(), auth.CertificatePrincipal(cert)))
+			context.Next()
+			return
+		}
 		if tokenString == "" {
-			context.JSON(http.StatusUnauthorized, gin.H{"error": "request does not contain an access token"})
+			context.JSON(http.StatusUnauthorized, gin.H{"error": "request does not contain an access token or an API key"})
 			context.Abort()
 			return
 		}
-		err := auth.ValidateToken(tokenString)
+		claims, err := auth.ParseToken(tokenString)
 		if err != nil {
 			context.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			context.Abort()
 			return
 		}
+		if claims.Id != "" {
+			revoked, err := authenticator.IsTokenRevoked(context.Request.Context(), claims.Id, claims.Subject)
+			if err != nil {
+				_ = context.Error(err)
+				context.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check the access token"})
+				context.Abort()
+				return
+			}
+			if revoked {
+				context.JSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
+				context.Abort()
+				return
+			}
+		}
+		context.Set(ClaimsKey, claims)
+		context.Request = context.Request.WithContext(
+			requestctx.WithPrincipal(context.Request.Context(), claims.Principal()))
 		context.Next()
 	}
 }
+
+// RequireScopes allows only principals granted all the scopes. Must be used after Auth.
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		principal := requestctx.Principal(context.Request.Context())
+		for _, scope := range scopes {
+			if principal == nil || !principal.HasScope(scope) {
+				context.JSON(http.StatusForbidden, gin.H{"error": "insufficient scope, required: " + scope})
+				context.Abort()
+				return
+			}
+		}
+		context.Next()
+	}
+}
+
+// verifiedClientCert returns the client certificate verified against the client CA bundle, if any.
+func verifiedClientCert(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
@@ -0,0 +1,16 @@
+package middlewares
+
+import (
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIP puts the IP address of the client into the request context.
+// Forwarding headers are taken into account only for the trusted proxies of the engine.
+func ClientIP() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.Request = context.Request.WithContext(
+			requestctx.WithClientIP(context.Request.Context(), context.ClientIP()))
+		context.Next()
+	}
+}
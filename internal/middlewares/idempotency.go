@@ -0,0 +1,124 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderIdempotencyKey is the request header of keys making retries of mutating requests safe.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// HeaderIdempotentReplayed marks responses replayed for a retried request.
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyStore keeps requests made with idempotency keys and their responses.
+type IdempotencyStore interface {
+	BeginIdempotentRequest(ctx context.Context, key, fingerprint string) (*models.IdempotentRequest, error)
+	CompleteIdempotentRequest(ctx context.Context, req *models.IdempotentRequest, resp *models.IdempotentResponse) error
+}
+
+// Idempotency handles a request with the Idempotency-Key header once per principal and key:
+// retries get the stored status and body of the first response, the same key with another method,
+// URI or body is rejected with 422, a retry while the first request is in progress gets 409.
+// Requests without the header are handled as usual. It must follow Auth, keys are scoped by the principal,
+// and RequireScopes, forbidden requests aren't stored.
+// The key is released if a handler panics, so the request can be retried.
+func Idempotency(store IdempotencyStore) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		key := context.GetHeader(HeaderIdempotencyKey)
+		if key == "" {
+			context.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			context.JSON(http.StatusBadRequest, gin.H{"error": "idempotency key is too long"})
+			context.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(context.Request.Body)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			context.Abort()
+			return
+		}
+		context.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		req, err := store.BeginIdempotentRequest(context.Request.Context(), key, fingerprint(context.Request, body))
+		if err != nil {
+			switch {
+			case errors.Is(err, models.ErrIdempotencyKeyReused):
+				context.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			case errors.Is(err, models.ErrIdempotentRequestInProgress):
+				context.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				_ = context.Error(err)
+				context.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check the idempotency key"})
+			}
+			context.Abort()
+			return
+		}
+		if req.Response != nil {
+			context.Header(HeaderIdempotentReplayed, "true")
+			context.Data(req.Response.StatusCode, req.Response.ContentType, req.Response.Body)
+			context.Abort()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: context.Writer}
+		context.Writer = recorder
+		completed := false
+		defer func() {
+			if !completed {
+				// A handler has panicked, the panic goes on to Recovery once the key is released.
+				_ = store.CompleteIdempotentRequest(context.Request.Context(), req,
+					&models.IdempotentResponse{StatusCode: http.StatusInternalServerError})
+			}
+		}()
+		context.Next()
+		completed = true
+
+		err = store.CompleteIdempotentRequest(context.Request.Context(), req, &models.IdempotentResponse{
+			StatusCode:  recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err != nil {
+			_ = context.Error(err)
+		}
+	}
+}
+
+// fingerprint hashes the method, the URI and the body of the request.
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bodyRecorder keeps a copy of the response body.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
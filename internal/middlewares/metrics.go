@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records the number and duration of requests by route template and status.
+func Metrics() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		start := time.Now()
+		context.Next()
+
+		route := context.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := context.Request.Method
+		status := strconv.Itoa(context.Writer.Status())
+
+		metrics.HTTPRequestsTotal.WithLabelValues(method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID takes the request ID from the X-Request-ID header or generates a new one,
+// echoes it in the response and puts it into the request context as the correlation ID.
+func RequestID() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		requestID := context.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = uuid.New().String()
+		}
+
+		context.Header(RequestIDHeader, requestID)
+		context.Request = context.Request.WithContext(
+			requestctx.WithCorrelationID(context.Request.Context(), requestID))
+		context.Next()
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// APIKey is the stored state of a key authenticating a service client.
+type APIKey struct {
+	ID         string
+	Name       string
+	Prefix     string // first characters of the key shown in listings
+	KeyHash    string
+	Scopes     []string
+	CreatedBy  string
+	ExpiresAt  *time.Time // never expires if nil
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// NewAPIKey describes an API key to be created by an administrator.
+type NewAPIKey struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// CreatedAPIKey is a created API key with the key itself, which is shown only once.
+type CreatedAPIKey struct {
+	*APIKey
+	Key string
+}
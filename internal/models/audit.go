@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actions of audit records.
+const (
+	AuditActionCreated  = "created"
+	AuditActionUpdated  = "updated"
+	AuditActionDeleted  = "deleted"
+	AuditActionReverted = "reverted" // updated to the state of a previous version
+	AuditActionRestored = "restored" // undeleted
+	AuditActionPurged   = "purged"   // deleted permanently
+)
+
+// Actor types of audit records, besides the principal types.
+const (
+	ActorSystem = "system" // internal callers, such as Kafka commands
+)
+
+// AuditRecord describes a mutation of a company. Before is empty for created companies, After is empty for deleted ones.
+type AuditRecord struct {
+	ID        int64
+	CompanyID string
+	Action    string // created | updated | deleted | reverted | restored | purged
+	Actor     string
+	ActorType string // user | api_key | certificate | system
+	ActorID   string
+	Before    json.RawMessage
+	After     json.RawMessage
+	RequestID string
+	ClientIP  string
+	CreatedAt time.Time
+}
+
+// AuditFilter describes a page of audit records of a company.
+type AuditFilter struct {
+	CompanyID string
+	Limit     int
+	Offset    int
+}
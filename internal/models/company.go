@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Company struct {
 	ID              string
 	Name            string
@@ -7,13 +9,54 @@ type Company struct {
 	EmployeesAmount int
 	Registered      bool
 	Type            string // Corporations | NonProfit | Cooperative | Sole Proprietorship
+	Version         int    // increments on every change, the number of the latest CompanyVersion
 }
 
 type CompanyPatch struct {
 	ID              string
+	Version         *int // expected current version, the patch fails with ErrVersionMismatch if it differs
 	Name            *string
 	Description     *string
 	EmployeesAmount *int
 	Registered      *bool
 	Type            *string // Corporations | NonProfit | Cooperative | Sole Proprietorship
 }
+
+// CompanyFilter describes which companies should be listed and in which order.
+type CompanyFilter struct {
+	Type         *string
+	Registered   *bool
+	EmployeesMin *int
+	EmployeesMax *int
+	NamePrefix   string
+
+	SortBy   string // id | name | employees_amount | registered | type | created_at | updated_at
+	SortDesc bool
+
+	Limit  int
+	Offset int
+	Cursor string // opaque token from CompanyList, takes precedence over Offset
+}
+
+// CompanyList is a page of companies.
+type CompanyList struct {
+	Companies  []*Company
+	Total      *int // counted only for offset pagination
+	NextCursor string
+	PrevCursor string
+}
+
+// CompanyVersion is a numbered state of a company, versions are numbered from 1.
+type CompanyVersion struct {
+	Version   int
+	Company   *Company
+	Deleted   bool // the version was added by deleting the company, Company is its last state
+	CreatedAt time.Time
+}
+
+// VersionFilter describes a page of versions of a company.
+type VersionFilter struct {
+	CompanyID string
+	Limit     int
+	Offset    int
+}
@@ -3,5 +3,26 @@ package models
 import "errors"
 
 var (
-	ErrCompanyNotFound = errors.New("company not found")
+	ErrCompanyNotFound     = errors.New("company not found")
+	ErrVersionNotFound     = errors.New("version not found")
+	ErrVersionMismatch     = errors.New("version mismatch")
+	ErrInvalidCursor       = errors.New("invalid cursor")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrUserAlreadyExists   = errors.New("user already exists")
+	ErrUserDisabled        = errors.New("user is disabled")
+	ErrInvalidCredentials  = errors.New("invalid username or password")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrForbidden           = errors.New("forbidden")
+	ErrAPIKeyNotFound      = errors.New("API key not found")
+	ErrInvalidAPIKey       = errors.New("invalid API key")
+
+	ErrIdempotencyKeyReused        = errors.New("idempotency key is already used for another request")
+	ErrIdempotentRequestInProgress = errors.New("request with the idempotency key is in progress")
+	ErrIdempotencyKeyTakenOver     = errors.New("idempotency key is taken over by a retry")
+
+	// Errors of the database, mapped by the repository from SQLSTATE codes.
+	ErrAlreadyExists    = errors.New("already exists")    // a unique constraint is violated
+	ErrConflict         = errors.New("conflict")          // a concurrent transaction interfered, the change can be retried
+	ErrInvalidReference = errors.New("invalid reference") // a referenced row or enum value doesn't exist
 )
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// IdempotentRequest is a request made with an idempotency key, keys are unique per principal.
+type IdempotentRequest struct {
+	Principal   string // type and ID of the principal
+	Key         string
+	Fingerprint string              // SHA-256 of the method, the URI and the body
+	Response    *IdempotentResponse // nil while the request is in progress
+	LockedUntil time.Time           // a request in progress longer is considered failed, its key may be taken over; identifies the holder
+	ExpiresAt   time.Time
+}
+
+// IdempotentResponse is the response replayed for retries of an idempotent request.
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// OutboxMessage is an event stored in the same transaction as the change it describes
+// and relayed to Kafka afterwards.
+type OutboxMessage struct {
+	ID            int64
+	Key           string
+	Headers       map[string]string
+	Payload       []byte
+	Attempts      int
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+}
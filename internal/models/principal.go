@@ -0,0 +1,28 @@
+package models
+
+// Types of principals.
+const (
+	PrincipalUser        = "user"
+	PrincipalAPIKey      = "api_key"
+	PrincipalCertificate = "certificate"
+)
+
+// Principal is the authenticated actor of a request.
+type Principal struct {
+	Type   string
+	ID     string // user or API key ID, certificate subject
+	Name   string
+	Email  string
+	Roles  []string
+	Scopes []string
+}
+
+// HasScope reports whether the principal is granted the scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+type User struct {
+	ID           string
+	Username     string
+	Email        string
+	PasswordHash string
+	Roles        []string
+	DisabledAt   *time.Time
+	CreatedAt    time.Time
+}
+
+// NewUser describes a user to be created by an administrator.
+type NewUser struct {
+	Username string
+	Email    string
+	Password string
+	Roles    []string
+}
+
+// AccessToken is a signed JWT authorizing requests of a user.
+type AccessToken struct {
+	ID        string // jti claim
+	Token     string
+	ExpiresAt time.Time
+}
+
+// Tokens are issued to a user on login and on refresh.
+type Tokens struct {
+	Access           *AccessToken
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// RefreshToken is the stored state of an issued refresh token.
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	FamilyID  string // tokens issued by rotation share the family of the first one
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
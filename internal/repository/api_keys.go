@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+)
+
+// CreateAPIKey inserts an API key.
+func (r *Repo) CreateAPIKey(ctx context.Context, k *models.APIKey) error {
+	r.log.With("id", k.ID, "name", k.Name, "scopes", k.Scopes, "created_by", k.CreatedBy).Debug("Repo.CreateAPIKey")
+
+	key := &APIKey{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		KeyHash:   k.KeyHash,
+		Scopes:    k.Scopes,
+		CreatedBy: k.CreatedBy,
+		ExpiresAt: k.ExpiresAt,
+	}
+	if _, err := r.conn(ctx).NewInsert().Model(key).Returning("created_at").Exec(ctx); err != nil {
+		return fmt.Errorf("insert API key: %w", mapError(err))
+	}
+	k.CreatedAt = key.CreatedAt
+
+	return nil
+}
+
+// GetAPIKeyByHash selects API key by hash. It returns nil if there is no such key.
+func (r *Repo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	r.log.Debug("Repo.GetAPIKeyByHash")
+
+	key := new(APIKey)
+	err := r.conn(ctx).NewSelect().Model(key).Where("key_hash = ?", keyHash).Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select API key: %w", err)
+	}
+
+	return key.toDomain(), nil
+}
+
+// ListAPIKeys selects all API keys, the newest first.
+func (r *Repo) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	r.log.Debug("Repo.ListAPIKeys")
+
+	var keys []APIKey
+	if err := r.conn(ctx).NewSelect().Model(&keys).Order("created_at DESC").Scan(ctx); err != nil {
+		return nil, fmt.Errorf("select API keys: %w", err)
+	}
+
+	result := make([]*models.APIKey, 0, len(keys))
+	for i := range keys {
+		result = append(result, keys[i].toDomain())
+	}
+
+	return result, nil
+}
+
+// RevokeAPIKey revokes the API key unless it is already revoked.
+func (r *Repo) RevokeAPIKey(ctx context.Context, id string) (affected int64, err error) {
+	r.log.With("id", id).Debug("Repo.RevokeAPIKey")
+
+	res, err := r.conn(ctx).NewUpdate().Model((*APIKey)(nil)).
+		Set("revoked_at = ?", time.Now()).
+		Where("id = ?", id).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("revoke API key: %w", mapError(err))
+	}
+
+	affected, err = res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("revoke API key rows affected: %w", err)
+	}
+
+	return affected, nil
+}
+
+// TouchAPIKey sets the last usage time of the API key. The row is updated only if the stored time
+// is older than usedAt by more than precision, so frequent requests don't write on every call.
+func (r *Repo) TouchAPIKey(ctx context.Context, id string, usedAt time.Time, precision time.Duration) error {
+	_, err := r.conn(ctx).NewUpdate().Model((*APIKey)(nil)).
+		Set("last_used_at = ?", usedAt).
+		Where("id = ?", id).
+		Where("last_used_at IS NULL OR last_used_at < ?", usedAt.Add(-precision)).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("update API key last usage: %w", err)
+	}
+
+	return nil
+}
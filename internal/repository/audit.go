@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+)
+
+// CreateAuditRecord inserts an audit record. Call it within RunInTx to store it atomically with the change.
+func (r *Repo) CreateAuditRecord(ctx context.Context, a *models.AuditRecord) error {
+	r.log.With("company_id", a.CompanyID, "action", a.Action, "actor", a.Actor).Debug("Repo.CreateAuditRecord")
+
+	record := &AuditRecord{
+		CompanyID: a.CompanyID,
+		Action:    a.Action,
+		Actor:     a.Actor,
+		ActorType: a.ActorType,
+		ActorID:   a.ActorID,
+		Before:    a.Before,
+		After:     a.After,
+		RequestID: a.RequestID,
+		ClientIP:  a.ClientIP,
+		CreatedAt: a.CreatedAt,
+	}
+	if _, err := r.conn(ctx).NewInsert().Model(record).Returning("id, created_at").Exec(ctx); err != nil {
+		return fmt.Errorf("insert audit record: %w", err)
+	}
+	a.ID, a.CreatedAt = record.ID, record.CreatedAt
+
+	return nil
+}
+
+// ListAuditRecords selects a page of audit records of the company, the newest first.
+func (r *Repo) ListAuditRecords(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditRecord, error) {
+	r.log.With("filter", filter).Debug("Repo.ListAuditRecords")
+
+	var records []AuditRecord
+	err := r.conn(ctx).NewSelect().Model(&records).
+		Where("company_id = ?", filter.CompanyID).
+		Order("id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("select audit records: %w", err)
+	}
+
+	result := make([]*models.AuditRecord, 0, len(records))
+	for i := range records {
+		result = append(result, records[i].toDomain())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+)
+
+// cursor points to a row of a company listing. It is handed to clients as an opaque token.
+type cursor struct {
+	SortBy   string      `json:"s"`
+	SortDesc bool        `json:"d,omitempty"`
+	Value    interface{} `json:"v,omitempty"`
+	ID       string      `json:"id"`
+	Backward bool        `json:"b,omitempty"`
+}
+
+func newCursor(filter *models.CompanyFilter, c *Company, backward bool) *cursor {
+	sortBy := sortColumn(filter)
+	return &cursor{
+		SortBy:   sortBy,
+		SortDesc: filter.SortDesc,
+		Value:    sortValue(c, sortBy),
+		ID:       c.ID,
+		Backward: backward,
+	}
+}
+
+func (c *cursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(token string) (*cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", models.ErrInvalidCursor, err)
+	}
+
+	c := &cursor{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("%w: %s", models.ErrInvalidCursor, err)
+	}
+
+	if c.ID == "" || !companySortColumns[c.SortBy] {
+		return nil, models.ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// sortValue returns the value of the sort column of the company in a form that survives JSON encoding.
+func sortValue(c *Company, column string) interface{} {
+	switch column {
+	case "name":
+		return c.Name
+	case "employees_amount":
+		return c.EmployeesAmount
+	case "registered":
+		return c.Registered
+	case "type":
+		return c.Type
+	case "created_at":
+		return c.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		if c.UpdatedAt == nil {
+			return "-infinity"
+		}
+		return c.UpdatedAt.Format(time.RFC3339Nano)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	filter := &models.CompanyFilter{SortBy: "employees_amount", SortDesc: true}
+	company := &Company{ID: "uuid1", EmployeesAmount: 42}
+
+	token := newCursor(filter, company, true).encode()
+	assert.NotEmpty(t, token)
+
+	cur, err := decodeCursor(token)
+	require.NoError(t, err)
+	assert.Equal(t, "employees_amount", cur.SortBy)
+	assert.True(t, cur.SortDesc)
+	assert.EqualValues(t, 42, cur.Value)
+	assert.Equal(t, "uuid1", cur.ID)
+	assert.True(t, cur.Backward)
+}
+
+func TestCursor_DefaultSort(t *testing.T) {
+	cur := newCursor(&models.CompanyFilter{}, &Company{ID: "uuid1", Name: "Name1"}, false)
+	assert.Equal(t, "name", cur.SortBy)
+	assert.Equal(t, "Name1", cur.Value)
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, token := range []string{
+		"not base64!",
+		"bm90IGpzb24",                 // "not json"
+		"eyJzIjoibmFtZSJ9",            // {"s":"name"}
+		"eyJzIjoiZm9vIiwiaWQiOiIxIn0", // {"s":"foo","id":"1"}
+	} {
+		_, err := decodeCursor(token)
+		assert.ErrorIs(t, err, models.ErrInvalidCursor, token)
+	}
+}
+
+func TestSortValue_Timestamps(t *testing.T) {
+	created := time.Date(2023, 1, 2, 3, 4, 5, 6000, time.UTC)
+	c := &Company{CreatedAt: created}
+
+	assert.Equal(t, "2023-01-02T03:04:05.000006Z", sortValue(c, "created_at"))
+	assert.Equal(t, "-infinity", sortValue(c, "updated_at"))
+
+	c.UpdatedAt = &created
+	assert.Equal(t, "2023-01-02T03:04:05.000006Z", sortValue(c, "updated_at"))
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/ezhdanovskiy/companies/internal/models"
@@ -16,8 +17,10 @@ type Company struct {
 	EmployeesAmount int        `bun:"employees_amount"`
 	Registered      bool       `bun:"registered"`
 	Type            string     `bun:"type"` // Corporations | NonProfit | Cooperative | Sole Proprietorship
+	Version         int        `bun:"version,nullzero,notnull,default:1"`
 	CreatedAt       time.Time  `bun:"created_at,nullzero,notnull,default:current_timestamp"`
 	UpdatedAt       *time.Time `bun:"updated_at"`
+	DeletedAt       time.Time  `bun:"deleted_at,soft_delete,nullzero"` // deleted rows are excluded from queries by default
 }
 
 func (c *Company) toDomain() *models.Company {
@@ -28,6 +31,7 @@ func (c *Company) toDomain() *models.Company {
 		EmployeesAmount: c.EmployeesAmount,
 		Registered:      c.Registered,
 		Type:            c.Type,
+		Version:         c.Version,
 	}
 }
 
@@ -41,3 +45,222 @@ func newCompany(m *models.Company) *Company {
 		Type:            m.Type,
 	}
 }
+
+type OutboxMessage struct {
+	bun.BaseModel `bun:"table:outbox,alias:o"`
+
+	ID            int64             `bun:"id,pk,autoincrement"`
+	Key           string            `bun:"key,notnull"`
+	Headers       map[string]string `bun:"headers,type:jsonb,notnull"`
+	Payload       []byte            `bun:"payload,notnull"`
+	Attempts      int               `bun:"attempts,notnull"`
+	LastError     string            `bun:"last_error,nullzero"`
+	NextAttemptAt time.Time         `bun:"next_attempt_at,nullzero,notnull,default:current_timestamp"`
+	CreatedAt     time.Time         `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func (m *OutboxMessage) toDomain() *models.OutboxMessage {
+	return &models.OutboxMessage{
+		ID:            m.ID,
+		Key:           m.Key,
+		Headers:       m.Headers,
+		Payload:       m.Payload,
+		Attempts:      m.Attempts,
+		NextAttemptAt: m.NextAttemptAt,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+type User struct {
+	bun.BaseModel `bun:"table:users,alias:u"`
+
+	ID           string     `bun:"id,pk"`
+	Username     string     `bun:"username,notnull"`
+	Email        string     `bun:"email,notnull"`
+	PasswordHash string     `bun:"password_hash,notnull"`
+	Roles        []string   `bun:"roles,array,notnull"`
+	DisabledAt   *time.Time `bun:"disabled_at"`
+	CreatedAt    time.Time  `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt    *time.Time `bun:"updated_at"`
+}
+
+func (u *User) toDomain() *models.User {
+	return &models.User{
+		ID:           u.ID,
+		Username:     u.Username,
+		Email:        u.Email,
+		PasswordHash: u.PasswordHash,
+		Roles:        u.Roles,
+		DisabledAt:   u.DisabledAt,
+		CreatedAt:    u.CreatedAt,
+	}
+}
+
+func newUser(m *models.User) *User {
+	return &User{
+		ID:           m.ID,
+		Username:     m.Username,
+		Email:        m.Email,
+		PasswordHash: m.PasswordHash,
+		Roles:        m.Roles,
+		DisabledAt:   m.DisabledAt,
+	}
+}
+
+type RefreshToken struct {
+	bun.BaseModel `bun:"table:refresh_tokens,alias:rt"`
+
+	ID        string     `bun:"id,pk"`
+	UserID    string     `bun:"user_id,notnull"`
+	FamilyID  string     `bun:"family_id,notnull"`
+	TokenHash string     `bun:"token_hash,notnull"`
+	ExpiresAt time.Time  `bun:"expires_at,notnull"`
+	UsedAt    *time.Time `bun:"used_at"`
+	RevokedAt *time.Time `bun:"revoked_at"`
+	CreatedAt time.Time  `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func (t *RefreshToken) toDomain() *models.RefreshToken {
+	return &models.RefreshToken{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		FamilyID:  t.FamilyID,
+		TokenHash: t.TokenHash,
+		ExpiresAt: t.ExpiresAt,
+		UsedAt:    t.UsedAt,
+		RevokedAt: t.RevokedAt,
+	}
+}
+
+type RevokedToken struct {
+	bun.BaseModel `bun:"table:revoked_tokens,alias:rvt"`
+
+	JTI       string    `bun:"jti,pk"`
+	ExpiresAt time.Time `bun:"expires_at,notnull"`
+	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+type APIKey struct {
+	bun.BaseModel `bun:"table:api_keys,alias:ak"`
+
+	ID         string     `bun:"id,pk"`
+	Name       string     `bun:"name,notnull"`
+	Prefix     string     `bun:"prefix,notnull"`
+	KeyHash    string     `bun:"key_hash,notnull"`
+	Scopes     []string   `bun:"scopes,array,notnull"`
+	CreatedBy  string     `bun:"created_by,notnull"`
+	ExpiresAt  *time.Time `bun:"expires_at"`
+	LastUsedAt *time.Time `bun:"last_used_at"`
+	RevokedAt  *time.Time `bun:"revoked_at"`
+	CreatedAt  time.Time  `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func (k *APIKey) toDomain() *models.APIKey {
+	return &models.APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		KeyHash:    k.KeyHash,
+		Scopes:     k.Scopes,
+		CreatedBy:  k.CreatedBy,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+type AuditRecord struct {
+	bun.BaseModel `bun:"table:audit_log,alias:al"`
+
+	ID        int64           `bun:"id,pk,autoincrement"`
+	CompanyID string          `bun:"company_id,notnull"`
+	Action    string          `bun:"action,notnull"`
+	Actor     string          `bun:"actor,notnull"`
+	ActorType string          `bun:"actor_type,notnull"`
+	ActorID   string          `bun:"actor_id,nullzero"`
+	Before    json.RawMessage `bun:"before,type:jsonb,nullzero"`
+	After     json.RawMessage `bun:"after,type:jsonb,nullzero"`
+	RequestID string          `bun:"request_id,nullzero"`
+	ClientIP  string          `bun:"client_ip,nullzero"`
+	CreatedAt time.Time       `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func (a *AuditRecord) toDomain() *models.AuditRecord {
+	return &models.AuditRecord{
+		ID:        a.ID,
+		CompanyID: a.CompanyID,
+		Action:    a.Action,
+		Actor:     a.Actor,
+		ActorType: a.ActorType,
+		ActorID:   a.ActorID,
+		Before:    a.Before,
+		After:     a.After,
+		RequestID: a.RequestID,
+		ClientIP:  a.ClientIP,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+type CompanyVersion struct {
+	bun.BaseModel `bun:"table:company_versions,alias:cv"`
+
+	CompanyID       string    `bun:"company_id,pk"`
+	Version         int       `bun:"version,pk"`
+	Name            string    `bun:"name"`
+	Description     string    `bun:"description"`
+	EmployeesAmount int       `bun:"employees_amount"`
+	Registered      bool      `bun:"registered"`
+	Type            string    `bun:"type"`
+	Deleted         bool      `bun:"deleted,notnull"`
+	CreatedAt       time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func (v *CompanyVersion) toDomain() *models.CompanyVersion {
+	return &models.CompanyVersion{
+		Version: v.Version,
+		Company: &models.Company{
+			ID:              v.CompanyID,
+			Name:            v.Name,
+			Description:     v.Description,
+			EmployeesAmount: v.EmployeesAmount,
+			Registered:      v.Registered,
+			Type:            v.Type,
+			Version:         v.Version,
+		},
+		Deleted:   v.Deleted,
+		CreatedAt: v.CreatedAt,
+	}
+}
+
+type IdempotencyKey struct {
+	bun.BaseModel `bun:"table:idempotency_keys,alias:ik"`
+
+	Principal   string    `bun:"principal,pk"`
+	Key         string    `bun:"key,pk"`
+	Fingerprint string    `bun:"fingerprint,notnull"`
+	StatusCode  *int      `bun:"status_code"`
+	ContentType string    `bun:"content_type,nullzero"`
+	Body        []byte    `bun:"body"`
+	LockedUntil time.Time `bun:"locked_until,notnull"`
+	ExpiresAt   time.Time `bun:"expires_at,notnull"`
+	CreatedAt   time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func (k *IdempotencyKey) toDomain() *models.IdempotentRequest {
+	r := &models.IdempotentRequest{
+		Principal:   k.Principal,
+		Key:         k.Key,
+		Fingerprint: k.Fingerprint,
+		LockedUntil: k.LockedUntil,
+		ExpiresAt:   k.ExpiresAt,
+	}
+	if k.StatusCode != nil {
+		r.Response = &models.IdempotentResponse{
+			StatusCode:  *k.StatusCode,
+			ContentType: k.ContentType,
+			Body:        k.Body,
+		}
+	}
+	return r
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+)
+
+// SQLSTATE codes mapped to domain errors, see https://www.postgresql.org/docs/current/errcodes-appendix.html.
+const (
+	sqlStateInvalidTextRepresentation = "22P02" // e.g. an unknown value of an enum
+	sqlStateForeignKeyViolation       = "23503"
+	sqlStateUniqueViolation           = "23505"
+	sqlStateExclusionViolation        = "23P01"
+	sqlStateSerializationFailure      = "40001"
+	sqlStateDeadlockDetected          = "40P01"
+	sqlStateLockNotAvailable          = "55P03"
+)
+
+// pgError is implemented by pgdriver.Error.
+type pgError interface {
+	error
+	Field(k byte) string
+}
+
+// mapError replaces errors of Postgres with domain errors by their SQLSTATE code.
+// The domain error names the violated constraint, if any, instead of the SQL message. Other errors are kept.
+func mapError(err error) error {
+	var pgErr pgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var domainErr error
+	switch pgErr.Field('C') {
+	case sqlStateUniqueViolation:
+		domainErr = models.ErrAlreadyExists
+	case sqlStateForeignKeyViolation, sqlStateInvalidTextRepresentation:
+		domainErr = models.ErrInvalidReference
+	case sqlStateExclusionViolation, sqlStateSerializationFailure, sqlStateDeadlockDetected, sqlStateLockNotAvailable:
+		domainErr = models.ErrConflict
+	default:
+		return err
+	}
+
+	if constraint := pgErr.Field('n'); constraint != "" {
+		return fmt.Errorf("%w: %s", domainErr, constraint)
+	}
+	return domainErr
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPgError mimics pgdriver.Error, which can't be created outside of the driver.
+type testPgError map[byte]string
+
+func (e testPgError) Field(k byte) string { return e[k] }
+func (e testPgError) Error() string       { return "ERROR: " + e['M'] + " (SQLSTATE=" + e['C'] + ")" }
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name: "unique violation",
+			err: testPgError{
+				'C': "23505",
+				'M': `duplicate key value violates unique constraint "companies_name_key"`,
+				'n': "companies_name_key",
+			},
+			wantErr: models.ErrAlreadyExists,
+			wantMsg: "already exists: companies_name_key",
+		},
+		{
+			name:    "unknown enum value",
+			err:     testPgError{'C': "22P02", 'M': `invalid input value for enum company_type: "Other"`},
+			wantErr: models.ErrInvalidReference,
+			wantMsg: "invalid reference",
+		},
+		{
+			name:    "deadlock",
+			err:     fmt.Errorf("update company: %w", testPgError{'C': "40P01", 'M': "deadlock detected"}),
+			wantErr: models.ErrConflict,
+			wantMsg: "conflict",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mapError(tt.err)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantMsg, err.Error()) // The SQL message isn't exposed
+		})
+	}
+}
+
+func TestMapError_Unmapped(t *testing.T) {
+	notNull := testPgError{'C': "23502", 'M': `null value in column "name" violates not-null constraint`}
+	assert.Equal(t, notNull, mapError(notNull))
+
+	other := errors.New("connection refused")
+	assert.Equal(t, other, mapError(other))
+
+	assert.NoError(t, mapError(nil))
+}
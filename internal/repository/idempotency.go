@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+)
+
+// CreateIdempotencyKey inserts the request in progress unless the principal has already used the key.
+// Expired keys are removed on the way, so they can be used again.
+func (r *Repo) CreateIdempotencyKey(ctx context.Context, req *models.IdempotentRequest) (created bool, err error) {
+	r.log.With("principal", req.Principal, "key", req.Key).Debug("Repo.CreateIdempotencyKey")
+
+	_, err = r.conn(ctx).NewDelete().Model((*IdempotencyKey)(nil)).
+		Where("expires_at < ?", time.Now()).
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+
+	res, err := r.conn(ctx).NewInsert().Model(&IdempotencyKey{
+		Principal:   req.Principal,
+		Key:         req.Key,
+		Fingerprint: req.Fingerprint,
+		LockedUntil: req.LockedUntil,
+		ExpiresAt:   req.ExpiresAt,
+	}).
+		On("CONFLICT (principal, key) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("insert idempotency key: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get affected rows: %w", err)
+	}
+
+	return affected == 1, nil
+}
+
+// GetIdempotencyKey selects the request made with the key. It returns nil if there is no such key.
+func (r *Repo) GetIdempotencyKey(ctx context.Context, principal, key string) (*models.IdempotentRequest, error) {
+	r.log.With("principal", principal, "key", key).Debug("Repo.GetIdempotencyKey")
+
+	k := new(IdempotencyKey)
+	err := r.conn(ctx).NewSelect().Model(k).
+		Where("principal = ?", principal).
+		Where("key = ?", key).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select idempotency key: %w", err)
+	}
+
+	return k.toDomain(), nil
+}
+
+// LockIdempotencyKey takes over the key of the request in progress whose lock has expired,
+// it sets the new lock of req. It returns false if the request is completed or locked by someone else.
+func (r *Repo) LockIdempotencyKey(ctx context.Context, req *models.IdempotentRequest) (locked bool, err error) {
+	r.log.With("principal", req.Principal, "key", req.Key).Debug("Repo.LockIdempotencyKey")
+
+	res, err := r.conn(ctx).NewUpdate().Model((*IdempotencyKey)(nil)).
+		Set("locked_until = ?", req.LockedUntil).
+		Where("principal = ?", req.Principal).
+		Where("key = ?", req.Key).
+		Where("fingerprint = ?", req.Fingerprint).
+		Where("status_code IS NULL").
+		Where("locked_until < ?", time.Now()).
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("update idempotency key: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get affected rows: %w", err)
+	}
+
+	return affected == 1, nil
+}
+
+// SaveIdempotentResponse stores the response of the request while it holds the key with the lock of req.
+// It fails with ErrIdempotencyKeyTakenOver if the key is locked by a retry.
+func (r *Repo) SaveIdempotentResponse(ctx context.Context, req *models.IdempotentRequest, resp *models.IdempotentResponse) error {
+	r.log.With("principal", req.Principal, "key", req.Key, "status_code", resp.StatusCode).Debug("Repo.SaveIdempotentResponse")
+
+	res, err := r.conn(ctx).NewUpdate().Model((*IdempotencyKey)(nil)).
+		Set("status_code = ?", resp.StatusCode).
+		Set("content_type = ?", resp.ContentType).
+		Set("body = ?", resp.Body).
+		Where("principal = ?", req.Principal).
+		Where("key = ?", req.Key).
+		Where("status_code IS NULL").
+		Where("locked_until = ?", req.LockedUntil).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("update idempotency key: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return models.ErrIdempotencyKeyTakenOver
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey deletes the key while the request holds it with the lock of req,
+// so the request can be made with it again. The key locked by a retry is kept.
+func (r *Repo) DeleteIdempotencyKey(ctx context.Context, req *models.IdempotentRequest) error {
+	r.log.With("principal", req.Principal, "key", req.Key).Debug("Repo.DeleteIdempotencyKey")
+
+	_, err := r.conn(ctx).NewDelete().Model((*IdempotencyKey)(nil)).
+		Where("principal = ?", req.Principal).
+		Where("key = ?", req.Key).
+		Where("status_code IS NULL").
+		Where("locked_until = ?", req.LockedUntil).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("delete idempotency key: %w", err)
+	}
+
+	return nil
+}
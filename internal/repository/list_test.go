@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+func TestPrepareListCompaniesQuery_Defaults(t *testing.T) {
+	q, err := prepareListCompaniesQuery(newTestSelect(), &models.CompanyFilter{Limit: 20}, nil)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`SELECT "c"."id", "c"."name", "c"."description", "c"."employees_amount", "c"."registered", "c"."type", `+
+			`"c"."version", "c"."created_at", "c"."updated_at", "c"."deleted_at" FROM "companies" AS "c" WHERE "c"."deleted_at" IS NULL `+
+			`ORDER BY "name" ASC, id ASC LIMIT 21`,
+		q.String())
+}
+
+func TestPrepareListCompaniesQuery_AllFilters(t *testing.T) {
+	companyType := "NonProfit"
+	registered := true
+	employeesMin := 10
+	employeesMax := 100
+
+	q, err := prepareListCompaniesQuery(newTestSelect(), &models.CompanyFilter{
+		Type:         &companyType,
+		Registered:   &registered,
+		EmployeesMin: &employeesMin,
+		EmployeesMax: &employeesMax,
+		NamePrefix:   "a_b%",
+		SortBy:       "employees_amount",
+		SortDesc:     true,
+		Limit:        5,
+		Offset:       10,
+	}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, q.String(), `WHERE (type = 'NonProfit') AND (registered = TRUE) `+
+		`AND (employees_amount >= 10) AND (employees_amount <= 100) AND (name LIKE 'a\_b\%%') AND "c"."deleted_at" IS NULL `+
+		`ORDER BY "employees_amount" DESC, id DESC LIMIT 6 OFFSET 10`)
+}
+
+func TestPrepareListCompaniesQuery_SortByID(t *testing.T) {
+	q, err := prepareListCompaniesQuery(newTestSelect(), &models.CompanyFilter{SortBy: "id"}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, q.String(), `ORDER BY "id" ASC`)
+	assert.NotContains(t, q.String(), `id ASC`)
+}
+
+func TestPrepareListCompaniesQuery_UnsupportedSort(t *testing.T) {
+	for _, sortBy := range []string{"name; DROP TABLE companies", "description"} {
+		_, err := prepareListCompaniesQuery(newTestSelect(), &models.CompanyFilter{SortBy: sortBy}, nil)
+		require.Error(t, err, sortBy)
+	}
+}
+
+func TestPrepareListCompaniesQuery_CursorForward(t *testing.T) {
+	cur := &cursor{SortBy: "employees_amount", Value: 100, ID: "uuid1"}
+	q, err := prepareListCompaniesQuery(newTestSelect(), &models.CompanyFilter{
+		SortBy: "employees_amount",
+		Limit:  10,
+		Offset: 30,
+	}, cur)
+	require.NoError(t, err)
+	assert.Contains(t, q.String(), `WHERE (("employees_amount", id) > (100, 'uuid1')) AND "c"."deleted_at" IS NULL `+
+		`ORDER BY "employees_amount" ASC, id ASC LIMIT 11`)
+	assert.NotContains(t, q.String(), "OFFSET")
+}
+
+func TestPrepareListCompaniesQuery_CursorBackwardDesc(t *testing.T) {
+	cur := &cursor{SortBy: "updated_at", SortDesc: true, Value: "-infinity", ID: "uuid1", Backward: true}
+	q, err := prepareListCompaniesQuery(newTestSelect(), &models.CompanyFilter{
+		SortBy:   "updated_at",
+		SortDesc: true,
+		Limit:    10,
+	}, cur)
+	require.NoError(t, err)
+	assert.Contains(t, q.String(), `WHERE ((COALESCE(updated_at, '-infinity'), id) > ('-infinity', 'uuid1')) AND "c"."deleted_at" IS NULL `+
+		`ORDER BY COALESCE(updated_at, '-infinity') ASC, id ASC LIMIT 11`)
+}
+
+func TestPrepareListCompaniesQuery_CursorByID(t *testing.T) {
+	cur := &cursor{SortBy: "id", SortDesc: true, ID: "uuid1"}
+	q, err := prepareListCompaniesQuery(newTestSelect(), &models.CompanyFilter{SortBy: "id", SortDesc: true}, cur)
+	require.NoError(t, err)
+	assert.Contains(t, q.String(), `WHERE (id < 'uuid1') AND "c"."deleted_at" IS NULL ORDER BY "id" DESC`)
+}
+
+func newTestSelect() *bun.SelectQuery {
+	return newTestDB().NewSelect().Model((*Company)(nil))
+}
+
+func newTestDB() *bun.DB {
+	return bun.NewDB(sql.OpenDB(pgdriver.NewConnector()), pgdialect.New())
+}
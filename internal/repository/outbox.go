@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/uptrace/bun"
+)
+
+// CreateOutboxMessage inserts a message to the outbox. Call it within RunInTx to store it atomically with the change.
+func (r *Repo) CreateOutboxMessage(ctx context.Context, m *models.OutboxMessage) error {
+	r.log.With("key", m.Key, "headers", m.Headers, "payload", string(m.Payload)).Debug("Repo.CreateOutboxMessage")
+
+	message := &OutboxMessage{
+		Key:     m.Key,
+		Headers: m.Headers,
+		Payload: m.Payload,
+	}
+	if _, err := r.conn(ctx).NewInsert().Model(message).Exec(ctx); err != nil {
+		return fmt.Errorf("insert outbox message: %w", err)
+	}
+	m.ID = message.ID
+
+	return nil
+}
+
+// LockOutboxMessages selects the oldest outbox messages in order and locks them until the end of the transaction.
+// Only one relay is allowed to drain the outbox at a time to keep the order,
+// so nothing is returned while another transaction holds the outbox lock. Must be called within RunInTx.
+func (r *Repo) LockOutboxMessages(ctx context.Context, limit int) ([]*models.OutboxMessage, error) {
+	var locked bool
+	err := r.conn(ctx).NewRaw("SELECT pg_try_advisory_xact_lock(hashtext('outbox'))").Scan(ctx, &locked)
+	if err != nil {
+		return nil, fmt.Errorf("lock outbox: %w", err)
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	var messages []*OutboxMessage
+	err = r.conn(ctx).NewSelect().Model(&messages).OrderExpr("id ASC").Limit(limit).For("UPDATE").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("select outbox messages: %w", err)
+	}
+
+	res := make([]*models.OutboxMessage, 0, len(messages))
+	for _, m := range messages {
+		res = append(res, m.toDomain())
+	}
+
+	return res, nil
+}
+
+// DeleteOutboxMessages deletes delivered outbox messages.
+func (r *Repo) DeleteOutboxMessages(ctx context.Context, ids ...int64) error {
+	r.log.With("ids", ids).Debug("Repo.DeleteOutboxMessages")
+
+	_, err := r.conn(ctx).NewDelete().Model((*OutboxMessage)(nil)).Where("id IN (?)", bun.In(ids)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("delete outbox messages: %w", err)
+	}
+
+	return nil
+}
+
+// PostponeOutboxMessage records a failed delivery attempt and schedules the next one.
+func (r *Repo) PostponeOutboxMessage(ctx context.Context, id int64, nextAttemptAt time.Time, reason string) error {
+	r.log.With("id", id, "next_attempt_at", nextAttemptAt, "reason", reason).Debug("Repo.PostponeOutboxMessage")
+
+	_, err := r.conn(ctx).NewUpdate().Model((*OutboxMessage)(nil)).
+		Set("attempts = attempts + 1").
+		Set("next_attempt_at = ?", nextAttemptAt).
+		Set("last_error = ?", reason).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("postpone outbox message: %w", err)
+	}
+
+	return nil
+}
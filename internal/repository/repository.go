@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ezhdanovskiy/companies/internal/models"
@@ -48,6 +49,20 @@ func MigrateUp(logger *zap.SugaredLogger, db *sql.DB, path string) error {
 	return nil
 }
 
+// MigrationState returns the version of the last applied migration and whether it failed halfway.
+func (r *Repo) MigrationState(ctx context.Context) (version int64, dirty bool, err error) {
+	err = r.db.NewRaw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(ctx, &version, &dirty)
+	if err != nil {
+		return 0, false, fmt.Errorf("select migration state: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// Ping checks the DB connection.
+func (r *Repo) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
 // NewRepo creates instance of repository using existing DB.
 func NewRepo(logger *zap.SugaredLogger, db *bun.DB) (*Repo, error) {
 	return &Repo{
@@ -61,24 +76,39 @@ func (r *Repo) CreateCompany(ctx context.Context, c *models.Company) error {
 	r.log.With("id", c.ID, "name", c.Name, "descr", c.Description, "amount", c.EmployeesAmount,
 		"registered", c.Registered, "type", c.Type).Debug("Repo.CreateCompany")
 
-	_, err := r.db.NewInsert().Model(newCompany(c)).Exec(ctx)
+	company := newCompany(c)
+	// A purged company keeps its versions, so its ID created again continues their numbering.
+	_, err := r.conn(ctx).NewInsert().Model(company).
+		Value("version", "(SELECT coalesce(max(version), 0) + 1 FROM company_versions WHERE company_id = ?)", company.ID).
+		Returning("version").
+		Exec(ctx)
 	if err != nil {
-		return err
+		return fmt.Errorf("insert company: %w", mapError(err))
 	}
+	c.Version = company.Version
 
 	return nil
 }
 
-// UpdateCompany insert a company.
+// UpdateCompany updates a company and increments its version.
+// If the patch has the expected version, other versions aren't updated.
 func (r *Repo) UpdateCompany(ctx context.Context, c *models.CompanyPatch) (affected int64, err error) {
 	r.log.With("id", c.ID, "name", c.Name, "descr", c.Description, "amount", c.EmployeesAmount,
 		"registered", c.Registered, "type", c.Type).Debug("Repo.CreateCompany")
 
 	company, fields := prepareCompanyPatch(c)
 
-	res, err := r.db.NewUpdate().Model(company).Column(fields...).WherePK().Exec(ctx)
+	q := r.conn(ctx).NewUpdate().Model(company).
+		Column(append(fields, "version")...).
+		Value("version", "version + 1").
+		WherePK()
+	if c.Version != nil {
+		q = q.Where("version = ?", *c.Version)
+	}
+
+	res, err := q.Exec(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("update company: %w", err)
+		return 0, fmt.Errorf("update company: %w", mapError(err))
 	}
 
 	affected, err = res.RowsAffected()
@@ -124,11 +154,15 @@ func prepareCompanyPatch(c *models.CompanyPatch) (company *Company, fields []str
 	return company, fields
 }
 
-// DeleteCompany deletes a company.
+// DeleteCompany marks a company deleted and increments its version, it is kept until purged.
 func (r *Repo) DeleteCompany(ctx context.Context, uuid string) (affected int64, err error) {
 	r.log.With("uuid", uuid).Debug("Repo.DeleteCompany")
 
-	res, err := r.db.NewDelete().Model(&Company{ID: uuid}).WherePK().Exec(ctx)
+	res, err := r.conn(ctx).NewUpdate().Model((*Company)(nil)).
+		Set("deleted_at = ?", time.Now()).
+		Set("version = version + 1").
+		Where("id = ?", uuid).
+		Exec(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("delete company: %w", err)
 	}
@@ -141,12 +175,93 @@ func (r *Repo) DeleteCompany(ctx context.Context, uuid string) (affected int64,
 	return affected, nil
 }
 
+// RestoreCompany clears the deletion mark of a deleted company and increments its version.
+func (r *Repo) RestoreCompany(ctx context.Context, uuid string) (affected int64, err error) {
+	r.log.With("uuid", uuid).Debug("Repo.RestoreCompany")
+
+	res, err := r.conn(ctx).NewUpdate().Model((*Company)(nil)).
+		WhereDeleted().
+		Set("deleted_at = NULL").
+		Set("updated_at = ?", time.Now()).
+		Set("version = version + 1").
+		Where("id = ?", uuid).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("restore company: %w", mapError(err))
+	}
+
+	affected, err = res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("restore company rows affected: %w", err)
+	}
+
+	return affected, nil
+}
+
+// PurgeCompanies permanently deletes up to limit companies deleted before the time and returns them.
+// Rows locked by other transactions, e.g. being restored, are skipped.
+func (r *Repo) PurgeCompanies(ctx context.Context, deletedBefore time.Time, limit int) ([]*models.Company, error) {
+	r.log.With("deleted_before", deletedBefore, "limit", limit).Debug("Repo.PurgeCompanies")
+
+	expired := r.conn(ctx).NewSelect().Model((*Company)(nil)).
+		Column("id").
+		WhereDeleted().
+		Where("deleted_at < ?", deletedBefore).
+		OrderExpr("deleted_at").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED")
+
+	var companies []*Company
+	_, err := r.conn(ctx).NewDelete().Model(&companies).
+		WhereDeleted().
+		ForceDelete().
+		Where("id IN (?)", expired).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("purge companies: %w", mapError(err))
+	}
+
+	result := make([]*models.Company, 0, len(companies))
+	for _, c := range companies {
+		result = append(result, c.toDomain())
+	}
+
+	return result, nil
+}
+
 // GetCompany selects company by uuid.
 func (r *Repo) GetCompany(ctx context.Context, uuid string) (*models.Company, error) {
 	r.log.With("uuid", uuid).Debug("Repo.GetCompany")
+	return r.getCompany(ctx, uuid, false, false)
+}
+
+// GetCompanyForUpdate selects company by uuid and locks it until the end of the transaction.
+// Must be called within RunInTx.
+func (r *Repo) GetCompanyForUpdate(ctx context.Context, uuid string) (*models.Company, error) {
+	r.log.With("uuid", uuid).Debug("Repo.GetCompanyForUpdate")
+	return r.getCompany(ctx, uuid, true, false)
+}
 
+// GetDeletedCompanyForUpdate selects deleted company by uuid and locks it until the end of the transaction.
+// Must be called within RunInTx.
+func (r *Repo) GetDeletedCompanyForUpdate(ctx context.Context, uuid string) (*models.Company, error) {
+	r.log.With("uuid", uuid).Debug("Repo.GetDeletedCompanyForUpdate")
+	return r.getCompany(ctx, uuid, true, true)
+}
+
+// getCompany selects the company unless it is deleted, or only if it is deleted.
+func (r *Repo) getCompany(ctx context.Context, uuid string, forUpdate, deleted bool) (*models.Company, error) {
 	company := new(Company)
-	err := r.db.NewSelect().Model(company).Where("id = ?", uuid).Scan(ctx)
+	q := r.conn(ctx).NewSelect().Model(company).Where("id = ?", uuid)
+	if forUpdate {
+		q = q.For("UPDATE")
+	}
+	if deleted {
+		q = q.WhereDeleted()
+	}
+
+	err := q.Scan(ctx)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -156,3 +271,169 @@ func (r *Repo) GetCompany(ctx context.Context, uuid string) (*models.Company, er
 
 	return company.toDomain(), nil
 }
+
+// companySortColumns whitelists the columns companies can be sorted by, each has a (column, id) index for keyset pagination.
+// Description isn't sortable, its values may exceed the size of a B-tree index entry.
+var companySortColumns = map[string]bool{
+	"id":               true,
+	"name":             true,
+	"employees_amount": true,
+	"registered":       true,
+	"type":             true,
+	"created_at":       true,
+	"updated_at":       true,
+}
+
+// ListCompanies selects a page of companies matching the filter.
+// Without a cursor the page is selected by offset and the total amount of matching companies is counted.
+// With a cursor the page is selected by keyset starting right after (or before) the row encoded in it.
+func (r *Repo) ListCompanies(ctx context.Context, filter *models.CompanyFilter) (*models.CompanyList, error) {
+	r.log.With("filter", filter).Debug("Repo.ListCompanies")
+
+	var cur *cursor
+	if filter.Cursor != "" {
+		var err error
+		cur, err = decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cur.SortBy != sortColumn(filter) || cur.SortDesc != filter.SortDesc {
+			return nil, fmt.Errorf("%w: sort order does not match", models.ErrInvalidCursor)
+		}
+	}
+
+	var companies []*Company
+	q, err := prepareListCompaniesQuery(r.conn(ctx).NewSelect().Model(&companies), filter, cur)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &models.CompanyList{}
+	if cur == nil {
+		total, err := q.ScanAndCount(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("select companies: %w", err)
+		}
+		list.Total = &total
+	} else {
+		if err := q.Scan(ctx); err != nil {
+			return nil, fmt.Errorf("select companies: %w", err)
+		}
+	}
+
+	// One extra row is selected to find out whether there is a page after this one.
+	hasMore := len(companies) > filter.Limit && filter.Limit > 0
+	if hasMore {
+		companies = companies[:filter.Limit]
+	}
+	backward := cur != nil && cur.Backward
+	if backward {
+		for i, j := 0, len(companies)-1; i < j; i, j = i+1, j-1 {
+			companies[i], companies[j] = companies[j], companies[i]
+		}
+	}
+
+	if len(companies) > 0 {
+		first, last := companies[0], companies[len(companies)-1]
+		hasNext := hasMore
+		hasPrev := (cur == nil && filter.Offset > 0) || (cur != nil && !backward)
+		if backward {
+			hasNext, hasPrev = true, hasMore
+		}
+
+		if hasNext {
+			list.NextCursor = newCursor(filter, last, false).encode()
+		}
+		if hasPrev {
+			list.PrevCursor = newCursor(filter, first, true).encode()
+		}
+	}
+
+	list.Companies = make([]*models.Company, 0, len(companies))
+	for _, c := range companies {
+		list.Companies = append(list.Companies, c.toDomain())
+	}
+
+	return list, nil
+}
+
+func prepareListCompaniesQuery(q *bun.SelectQuery, filter *models.CompanyFilter, cur *cursor) (*bun.SelectQuery, error) {
+	if filter.Type != nil {
+		q = q.Where("type = ?", *filter.Type)
+	}
+
+	if filter.Registered != nil {
+		q = q.Where("registered = ?", *filter.Registered)
+	}
+
+	if filter.EmployeesMin != nil {
+		q = q.Where("employees_amount >= ?", *filter.EmployeesMin)
+	}
+
+	if filter.EmployeesMax != nil {
+		q = q.Where("employees_amount <= ?", *filter.EmployeesMax)
+	}
+
+	if filter.NamePrefix != "" {
+		q = q.Where("name LIKE ?", likeEscaper.Replace(filter.NamePrefix)+"%")
+	}
+
+	sortBy := sortColumn(filter)
+	if !companySortColumns[sortBy] {
+		return nil, fmt.Errorf("unsupported sort column %q", sortBy)
+	}
+
+	desc := filter.SortDesc
+	if cur != nil {
+		// Walking backward means selecting rows in reversed order and reversing them afterwards.
+		if cur.Backward {
+			desc = !desc
+		}
+
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		if sortBy == "id" {
+			q = q.Where("id "+op+" ?", cur.ID)
+		} else {
+			q = q.Where("(?, id) "+op+" (?, ?)", sortExpr(sortBy), cur.Value, cur.ID)
+		}
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	q = q.OrderExpr("? "+direction, sortExpr(sortBy))
+	if sortBy != "id" {
+		q = q.OrderExpr("id " + direction)
+	}
+
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit + 1)
+	}
+	if cur == nil {
+		q = q.Offset(filter.Offset)
+	}
+
+	return q, nil
+}
+
+func sortColumn(filter *models.CompanyFilter) string {
+	if filter.SortBy == "" {
+		return "name"
+	}
+	return filter.SortBy
+}
+
+// sortExpr returns the expression companies are ordered by.
+// updated_at is nullable, so NULLs are replaced to keep the keyset comparison total.
+func sortExpr(column string) interface{} {
+	if column == "updated_at" {
+		return bun.Safe("COALESCE(updated_at, '-infinity')")
+	}
+	return bun.Ident(column)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// CreateRefreshToken inserts a refresh token.
+func (r *Repo) CreateRefreshToken(ctx context.Context, t *models.RefreshToken) error {
+	r.log.With("id", t.ID, "user_id", t.UserID, "family_id", t.FamilyID).Debug("Repo.CreateRefreshToken")
+
+	token := &RefreshToken{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		FamilyID:  t.FamilyID,
+		TokenHash: t.TokenHash,
+		ExpiresAt: t.ExpiresAt,
+	}
+	if _, err := r.conn(ctx).NewInsert().Model(token).Exec(ctx); err != nil {
+		return fmt.Errorf("insert refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// GetRefreshTokenForUpdate selects refresh token by hash and locks it until the end of the transaction.
+// It returns nil if there is no such token. Must be called within RunInTx.
+func (r *Repo) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	r.log.Debug("Repo.GetRefreshTokenForUpdate")
+
+	token := new(RefreshToken)
+	err := r.conn(ctx).NewSelect().Model(token).Where("token_hash = ?", tokenHash).For("UPDATE").Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select refresh token: %w", err)
+	}
+
+	return token.toDomain(), nil
+}
+
+// MarkRefreshTokenUsed marks the refresh token as exchanged for new tokens.
+func (r *Repo) MarkRefreshTokenUsed(ctx context.Context, id string) error {
+	r.log.With("id", id).Debug("Repo.MarkRefreshTokenUsed")
+
+	_, err := r.conn(ctx).NewUpdate().Model((*RefreshToken)(nil)).
+		Set("used_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("update refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes all not yet revoked refresh tokens of the family.
+func (r *Repo) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	r.log.With("family_id", familyID).Debug("Repo.RevokeRefreshTokenFamily")
+
+	_, err := r.conn(ctx).NewUpdate().Model((*RefreshToken)(nil)).
+		Set("revoked_at = ?", time.Now()).
+		Where("family_id = ?", familyID).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("revoke refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes all not yet revoked refresh tokens of the user.
+func (r *Repo) RevokeUserRefreshTokens(ctx context.Context, username string) error {
+	r.log.With("username", username).Debug("Repo.RevokeUserRefreshTokens")
+
+	_, err := r.conn(ctx).NewUpdate().Model((*RefreshToken)(nil)).
+		Set("revoked_at = ?", time.Now()).
+		Where("user_id = (?)", r.conn(ctx).NewSelect().Model((*User)(nil)).Column("id").Where("username = ?", username)).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("revoke refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeToken adds the access token ID to the revocation list until the token expires.
+// Revocations of already expired tokens are removed from the list on the way.
+func (r *Repo) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.log.With("jti", jti, "expires_at", expiresAt).Debug("Repo.RevokeToken")
+
+	_, err := r.conn(ctx).NewInsert().Model(&RevokedToken{JTI: jti, ExpiresAt: expiresAt}).
+		On("CONFLICT (jti) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("insert revoked token: %w", err)
+	}
+
+	_, err = r.conn(ctx).NewDelete().Model((*RevokedToken)(nil)).
+		Where("expires_at < ?", time.Now()).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("delete expired revoked tokens: %w", err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked checks whether the access token ID is in the revocation list or the user of the token is disabled.
+func (r *Repo) IsTokenRevoked(ctx context.Context, jti, userID string) (bool, error) {
+	var revoked bool
+	err := prepareTokenRevokedQuery(r.conn(ctx), jti, userID).Scan(ctx, &revoked)
+	if err != nil {
+		return false, fmt.Errorf("select revoked token: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// prepareTokenRevokedQuery checks the user only when the token subject is a UUID,
+// any other subject can't match a user and would fail the cast to the id column type.
+func prepareTokenRevokedQuery(db bun.IDB, jti, userID string) *bun.SelectQuery {
+	revoked := db.NewSelect().Model((*RevokedToken)(nil)).Where("jti = ?", jti)
+	if _, err := uuid.Parse(userID); err != nil {
+		return db.NewSelect().ColumnExpr("EXISTS (?)", revoked)
+	}
+
+	return db.NewSelect().ColumnExpr("EXISTS (?) OR EXISTS (?)",
+		revoked,
+		db.NewSelect().Model((*User)(nil)).Where("id = ?", userID).Where("disabled_at IS NOT NULL"),
+	)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepareTokenRevokedQuery(t *testing.T) {
+	q := prepareTokenRevokedQuery(newTestDB(), "jti1", "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	assert.Contains(t, q.String(), `FROM "revoked_tokens" AS "rvt" WHERE (jti = 'jti1')) OR EXISTS (`)
+	assert.Contains(t, q.String(),
+		`FROM "users" AS "u" WHERE (id = '6ba7b810-9dad-11d1-80b4-00c04fd430c8') AND (disabled_at IS NOT NULL))`)
+}
+
+func TestPrepareTokenRevokedQuery_NonUUIDSubject(t *testing.T) {
+	for _, subject := range []string{"", "service-account"} {
+		q := prepareTokenRevokedQuery(newTestDB(), "jti1", subject)
+		assert.Equal(t,
+			`SELECT EXISTS (SELECT "rvt"."jti", "rvt"."expires_at", "rvt"."created_at" FROM "revoked_tokens" AS "rvt" WHERE (jti = 'jti1'))`,
+			q.String(), subject)
+	}
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+)
+
+type txKey struct{}
+
+// RunInTx runs fn in a transaction. Repository methods called with the context passed to fn use this transaction.
+// Nested calls join the outer transaction.
+func (r *Repo) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(bun.Tx); ok {
+		return fn(ctx)
+	}
+
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+	return mapError(err) // Conflicts with concurrent transactions may fail any statement or the commit
+}
+
+// conn returns the transaction started by RunInTx or the DB itself.
+func (r *Repo) conn(ctx context.Context) bun.IDB {
+	if tx, ok := ctx.Value(txKey{}).(bun.Tx); ok {
+		return tx
+	}
+	return r.db
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/uptrace/bun"
+)
+
+// CreateUser inserts a user.
+func (r *Repo) CreateUser(ctx context.Context, u *models.User) error {
+	r.log.With("id", u.ID, "username", u.Username, "email", u.Email, "roles", u.Roles).Debug("Repo.CreateUser")
+
+	user := newUser(u)
+	if _, err := r.conn(ctx).NewInsert().Model(user).Returning("created_at").Exec(ctx); err != nil {
+		return fmt.Errorf("insert user: %w", mapError(err))
+	}
+	u.CreatedAt = user.CreatedAt
+
+	return nil
+}
+
+// GetUser selects user by id. It returns nil if there is no such user.
+func (r *Repo) GetUser(ctx context.Context, id string) (*models.User, error) {
+	r.log.With("id", id).Debug("Repo.GetUser")
+	return r.getUser(ctx, "id", id)
+}
+
+// GetUserByUsername selects user by username. It returns nil if there is no such user.
+func (r *Repo) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	r.log.With("username", username).Debug("Repo.GetUserByUsername")
+	return r.getUser(ctx, "username", username)
+}
+
+func (r *Repo) getUser(ctx context.Context, column, value string) (*models.User, error) {
+	user := new(User)
+	err := r.conn(ctx).NewSelect().Model(user).Where("? = ?", bun.Ident(column), value).Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select user: %w", err)
+	}
+
+	return user.toDomain(), nil
+}
+
+// SetUserDisabled disables or enables a user.
+func (r *Repo) SetUserDisabled(ctx context.Context, username string, disabled bool) (affected int64, err error) {
+	r.log.With("username", username, "disabled", disabled).Debug("Repo.SetUserDisabled")
+
+	now := time.Now()
+	user := &User{UpdatedAt: &now}
+	if disabled {
+		user.DisabledAt = &now
+	}
+
+	res, err := r.conn(ctx).NewUpdate().Model(user).
+		Column("disabled_at", "updated_at").
+		Where("username = ?", username).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("update user: %w", err)
+	}
+
+	affected, err = res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("update user rows affected: %w", err)
+	}
+
+	return affected, nil
+}
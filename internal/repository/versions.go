@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+)
+
+// CreateCompanyVersion inserts the next version of the company and sets its number.
+// Call it within RunInTx with the company locked, so concurrent changes don't take the same number.
+func (r *Repo) CreateCompanyVersion(ctx context.Context, v *models.CompanyVersion) error {
+	r.log.With("id", v.Company.ID, "deleted", v.Deleted).Debug("Repo.CreateCompanyVersion")
+
+	version := &CompanyVersion{
+		CompanyID:       v.Company.ID,
+		Name:            v.Company.Name,
+		Description:     v.Company.Description,
+		EmployeesAmount: v.Company.EmployeesAmount,
+		Registered:      v.Company.Registered,
+		Type:            v.Company.Type,
+		Deleted:         v.Deleted,
+		CreatedAt:       v.CreatedAt,
+	}
+	_, err := r.conn(ctx).NewInsert().Model(version).
+		Value("version", "(SELECT coalesce(max(version), 0) + 1 FROM company_versions WHERE company_id = ?)", version.CompanyID).
+		Returning("version, created_at").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("insert company version: %w", mapError(err))
+	}
+	v.Version, v.CreatedAt = version.Version, version.CreatedAt
+
+	return nil
+}
+
+// ListCompanyVersions selects a page of versions of the company in order.
+func (r *Repo) ListCompanyVersions(ctx context.Context, filter *models.VersionFilter) ([]*models.CompanyVersion, error) {
+	r.log.With("filter", filter).Debug("Repo.ListCompanyVersions")
+
+	var versions []CompanyVersion
+	err := r.conn(ctx).NewSelect().Model(&versions).
+		Where("company_id = ?", filter.CompanyID).
+		Order("version").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("select company versions: %w", err)
+	}
+
+	result := make([]*models.CompanyVersion, 0, len(versions))
+	for i := range versions {
+		result = append(result, versions[i].toDomain())
+	}
+
+	return result, nil
+}
+
+// GetCompanyVersion selects the version of the company. It returns nil if there is no such version.
+func (r *Repo) GetCompanyVersion(ctx context.Context, companyID string, version int) (*models.CompanyVersion, error) {
+	r.log.With("id", companyID, "version", version).Debug("Repo.GetCompanyVersion")
+
+	v := new(CompanyVersion)
+	err := r.conn(ctx).NewSelect().Model(v).
+		Where("company_id = ?", companyID).
+		Where("version = ?", version).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select company version: %w", err)
+	}
+
+	return v.toDomain(), nil
+}
+
+// GetCompanyVersionAsOf selects the version of the company that was current at the time.
+// It returns nil if the company didn't exist yet.
+func (r *Repo) GetCompanyVersionAsOf(ctx context.Context, companyID string, asOf time.Time) (*models.CompanyVersion, error) {
+	r.log.With("id", companyID, "as_of", asOf).Debug("Repo.GetCompanyVersionAsOf")
+
+	v := new(CompanyVersion)
+	err := r.conn(ctx).NewSelect().Model(v).
+		Where("company_id = ?", companyID).
+		Where("created_at <= ?", asOf).
+		Order("version DESC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select company version: %w", err)
+	}
+
+	return v.toDomain(), nil
+}
@@ -0,0 +1,56 @@
+// Package requestctx carries request scoped values through context.Context.
+package requestctx
+
+import (
+	"context"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+)
+
+type correlationIDKey struct{}
+
+type principalKey struct{}
+
+type clientIPKey struct{}
+
+// WithCorrelationID returns a copy of ctx that carries the correlation ID.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// CorrelationID returns the correlation ID carried by ctx or an empty string.
+func CorrelationID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	correlationID, _ := ctx.Value(correlationIDKey{}).(string)
+	return correlationID
+}
+
+// WithPrincipal returns a copy of ctx that carries the authenticated actor.
+func WithPrincipal(ctx context.Context, principal *models.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// Principal returns the authenticated actor carried by ctx or nil for internal callers.
+func Principal(ctx context.Context) *models.Principal {
+	if ctx == nil {
+		return nil
+	}
+	principal, _ := ctx.Value(principalKey{}).(*models.Principal)
+	return principal
+}
+
+// WithClientIP returns a copy of ctx that carries the IP address of the client.
+func WithClientIP(ctx context.Context, clientIP string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, clientIP)
+}
+
+// ClientIP returns the IP address of the client carried by ctx or an empty string.
+func ClientIP(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	clientIP, _ := ctx.Value(clientIPKey{}).(string)
+	return clientIP
+}
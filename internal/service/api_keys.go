@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// apiKeyUsagePrecision limits how often the last usage time of an API key is written.
+const apiKeyUsagePrecision = time.Minute
+
+// CreateAPIKey creates an API key with the scopes. The caller can't grant scopes it doesn't have.
+func (s *Service) CreateAPIKey(ctx context.Context, newKey *models.NewAPIKey) (_ *models.CreatedAPIKey, err error) {
+	s.log.With("name", newKey.Name, "scopes", newKey.Scopes, "actor", actor(ctx)).Debug("Service.CreateAPIKey")
+	ctx, span := s.startSpan(ctx, "Service.CreateAPIKey", attribute.String("api_key.name", newKey.Name))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.authorize(ctx, auth.ScopeUsersManage); err != nil {
+		return nil, err
+	}
+	for _, scope := range newKey.Scopes {
+		if err := s.authorize(ctx, scope); err != nil {
+			return nil, err
+		}
+	}
+
+	key, hash, prefix, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("new API key: %w", err)
+	}
+
+	apiKey := &models.APIKey{
+		ID:        uuid.New().String(),
+		Name:      newKey.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    newKey.Scopes,
+		CreatedBy: actor(ctx),
+		ExpiresAt: newKey.ExpiresAt,
+	}
+	if err := s.repo.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// ListAPIKeys returns all API keys including expired and revoked ones.
+func (s *Service) ListAPIKeys(ctx context.Context) (_ []*models.APIKey, err error) {
+	s.log.Debug("Service.ListAPIKeys")
+	ctx, span := s.startSpan(ctx, "Service.ListAPIKeys")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.authorize(ctx, auth.ScopeUsersManage); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListAPIKeys(ctx)
+}
+
+// RevokeAPIKey revokes the API key, it returns models.ErrAPIKeyNotFound if the key doesn't exist or is already revoked.
+func (s *Service) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	s.log.With("id", id, "actor", actor(ctx)).Debug("Service.RevokeAPIKey")
+	ctx, span := s.startSpan(ctx, "Service.RevokeAPIKey", attribute.String("api_key.id", id))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.authorize(ctx, auth.ScopeUsersManage); err != nil {
+		return err
+	}
+
+	affected, err := s.repo.RevokeAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey returns the principal of a valid API key and records the key usage.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key string) (_ *models.Principal, err error) {
+	ctx, span := s.startSpan(ctx, "Service.AuthenticateAPIKey")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	stored, err := s.repo.GetAPIKeyByHash(ctx, auth.HashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if stored == nil || stored.RevokedAt != nil || stored.ExpiresAt != nil && !now.Before(*stored.ExpiresAt) {
+		return nil, models.ErrInvalidAPIKey
+	}
+
+	// Failed usage tracking doesn't reject the request.
+	if err := s.repo.TouchAPIKey(ctx, stored.ID, now, apiKeyUsagePrecision); err != nil {
+		s.log.With("id", stored.ID, "error", err).Warn("Failed to record API key usage")
+	}
+
+	return &models.Principal{
+		Type:   models.PrincipalAPIKey,
+		ID:     stored.ID,
+		Name:   stored.Name,
+		Scopes: stored.Scopes,
+	}, nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CreateAPIKey(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	adminCtx := requestctx.WithPrincipal(context.Background(), &models.Principal{
+		Type:   models.PrincipalUser,
+		Name:   "admin",
+		Scopes: auth.ScopesForRoles([]string{auth.RoleAdmin}),
+	})
+	expiresAt := time.Now().Add(time.Hour)
+
+	var stored *models.APIKey
+	ts.mockRepo.EXPECT().CreateAPIKey(adminCtx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, k *models.APIKey) error {
+			stored = k
+			return nil
+		})
+
+	created, err := ts.svc.CreateAPIKey(adminCtx, &models.NewAPIKey{
+		Name:      "batch",
+		Scopes:    []string{auth.ScopeCompaniesWrite},
+		ExpiresAt: &expiresAt,
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, stored)
+	assert.True(t, strings.HasPrefix(created.Key, auth.APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(created.Key, stored.Prefix))
+	assert.Equal(t, auth.HashAPIKey(created.Key), stored.KeyHash)
+	assert.Equal(t, "batch", stored.Name)
+	assert.Equal(t, []string{auth.ScopeCompaniesWrite}, stored.Scopes)
+	assert.Equal(t, "admin", stored.CreatedBy)
+	assert.Equal(t, &expiresAt, stored.ExpiresAt)
+}
+
+func TestService_CreateAPIKey_ScopeNotHeld(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	managerCtx := requestctx.WithPrincipal(context.Background(), &models.Principal{
+		Name:   "manager",
+		Scopes: []string{auth.ScopeUsersManage, auth.ScopeCompaniesRead},
+	})
+
+	_, err := ts.svc.CreateAPIKey(managerCtx, &models.NewAPIKey{
+		Name:   "batch",
+		Scopes: []string{auth.ScopeCompaniesDelete},
+	})
+	assert.ErrorIs(t, err, models.ErrForbidden) // Scopes can't be escalated through API keys
+}
+
+func TestService_RevokeAPIKey_NotFound(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	ts.mockRepo.EXPECT().RevokeAPIKey(ctx, "key-id").
+		Return(int64(0), nil)
+
+	err := ts.svc.RevokeAPIKey(ctx, "key-id")
+	assert.ErrorIs(t, err, models.ErrAPIKeyNotFound)
+}
+
+func TestService_AuthenticateAPIKey(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	now := time.Now()
+	ts.svc.now = func() time.Time { return now }
+
+	stored := &models.APIKey{ID: "key-id", Name: "batch", Scopes: []string{auth.ScopeCompaniesRead}}
+	ts.mockRepo.EXPECT().GetAPIKeyByHash(ctx, auth.HashAPIKey("ck_key")).
+		Return(stored, nil)
+	ts.mockRepo.EXPECT().TouchAPIKey(ctx, "key-id", now, apiKeyUsagePrecision).
+		Return(nil)
+
+	principal, err := ts.svc.AuthenticateAPIKey(ctx, "ck_key")
+	require.NoError(t, err)
+	assert.Equal(t, models.PrincipalAPIKey, principal.Type)
+	assert.Equal(t, "key-id", principal.ID)
+	assert.Equal(t, "batch", principal.Name)
+	assert.True(t, principal.HasScope(auth.ScopeCompaniesRead))
+}
+
+func TestService_AuthenticateAPIKey_Invalid(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name   string
+		stored *models.APIKey
+	}{
+		{name: "unknown", stored: nil},
+		{name: "expired", stored: &models.APIKey{ID: "key-id", ExpiresAt: &past}},
+		{name: "revoked", stored: &models.APIKey{ID: "key-id", RevokedAt: &past}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t)
+			defer ts.Finish()
+
+			ts.mockRepo.EXPECT().GetAPIKeyByHash(ctx, auth.HashAPIKey("ck_key")).
+				Return(tt.stored, nil)
+
+			_, err := ts.svc.AuthenticateAPIKey(ctx, "ck_key")
+			assert.ErrorIs(t, err, models.ErrInvalidAPIKey)
+		})
+	}
+}
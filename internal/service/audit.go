@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/ezhdanovskiy/companies/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// audit stores the audit record of a company mutation by the principal of ctx.
+// It must be called in the transaction of the change the record describes.
+func (s *Service) audit(ctx context.Context, action, companyID string, before, after *models.Company) error {
+	record := &models.AuditRecord{
+		CompanyID: companyID,
+		Action:    action,
+		Actor:     actor(ctx),
+		ActorType: models.ActorSystem,
+		RequestID: requestctx.CorrelationID(ctx),
+		ClientIP:  requestctx.ClientIP(ctx),
+		CreatedAt: s.now(),
+	}
+	if principal := requestctx.Principal(ctx); principal != nil {
+		record.ActorType, record.ActorID = principal.Type, principal.ID
+	}
+
+	var err error
+	if before != nil {
+		if record.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if record.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.CreateAuditRecord(ctx, record)
+}
+
+// GetCompanyAudit returns a page of the audit records of the company, the newest first.
+func (s *Service) GetCompanyAudit(ctx context.Context, filter *models.AuditFilter) (_ []*models.AuditRecord, err error) {
+	s.log.With("filter", filter, "actor", actor(ctx)).Debug("Service.GetCompanyAudit")
+	ctx, span := s.startSpan(ctx, "Service.GetCompanyAudit", attribute.String("company.id", filter.CompanyID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.authorize(ctx, auth.ScopeCompaniesRead); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListAuditRecords(ctx, filter)
+}
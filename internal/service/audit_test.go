@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CreateCompany_Audit(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	ts.svc.now = func() time.Time { return now }
+
+	company := &models.Company{ID: "test-uuid", Name: "Name"}
+	reqCtx := requestctx.WithCorrelationID(context.Background(), "request-id")
+	reqCtx = requestctx.WithClientIP(reqCtx, "192.0.2.1")
+	reqCtx = requestctx.WithPrincipal(reqCtx, &models.Principal{
+		Type:   models.PrincipalUser,
+		ID:     "user-id",
+		Name:   "editor",
+		Scopes: []string{auth.ScopeCompaniesWrite},
+	})
+
+	ts.mockRepo.EXPECT().CreateCompany(reqCtx, company).
+		Return(nil)
+	ts.mockRepo.EXPECT().CreateCompanyVersion(reqCtx, gomock.Any()).
+		Return(nil)
+	ts.mockRepo.EXPECT().CreateOutboxMessage(reqCtx, gomock.Any()).
+		Return(nil)
+	ts.mockRepo.EXPECT().CreateAuditRecord(reqCtx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, record *models.AuditRecord) error {
+			assert.Equal(t, "test-uuid", record.CompanyID)
+			assert.Equal(t, models.AuditActionCreated, record.Action)
+			assert.Equal(t, "editor", record.Actor)
+			assert.Equal(t, models.PrincipalUser, record.ActorType)
+			assert.Equal(t, "user-id", record.ActorID)
+			assert.Nil(t, record.Before)
+			assert.JSONEq(t, mustMarshal(t, company), string(record.After))
+			assert.Equal(t, "request-id", record.RequestID)
+			assert.Equal(t, "192.0.2.1", record.ClientIP)
+			assert.Equal(t, now, record.CreatedAt)
+			return nil
+		})
+
+	err := ts.svc.CreateCompany(reqCtx, company)
+	require.NoError(t, err)
+}
+
+func TestService_CreateCompany_AuditError(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	company := &models.Company{ID: "test-uuid"}
+	expectedErr := errors.New("CreateAuditRecordError")
+
+	ts.mockRepo.EXPECT().CreateCompany(ctx, company).
+		Return(nil)
+	ts.mockRepo.EXPECT().CreateCompanyVersion(ctx, gomock.Any()).
+		Return(nil)
+	ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+		Return(nil)
+	ts.mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, record *models.AuditRecord) error {
+			assert.Equal(t, models.ActorSystem, record.Actor)
+			assert.Equal(t, models.ActorSystem, record.ActorType)
+			return expectedErr
+		})
+
+	err := ts.svc.CreateCompany(ctx, company)
+	require.Error(t, err) // The change is rolled back if the audit record can't be stored
+	assert.Equal(t, expectedErr, err)
+}
+
+func TestService_GetCompanyAudit(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	filter := &models.AuditFilter{CompanyID: "test-uuid", Limit: 20}
+	expected := []*models.AuditRecord{{ID: 1, CompanyID: "test-uuid", Action: models.AuditActionCreated}}
+
+	ts.mockRepo.EXPECT().ListAuditRecords(ctx, filter).
+		Return(expected, nil)
+
+	records, err := ts.svc.GetCompanyAudit(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, expected, records)
+}
+
+func TestService_GetCompanyAudit_Forbidden(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	apiKeyCtx := requestctx.WithPrincipal(context.Background(), &models.Principal{
+		Type:   models.PrincipalAPIKey,
+		Name:   "writer",
+		Scopes: []string{auth.ScopeCompaniesWrite},
+	})
+
+	_, err := ts.svc.GetCompanyAudit(apiKeyCtx, &models.AuditFilter{CompanyID: "test-uuid"})
+	assert.ErrorIs(t, err, models.ErrForbidden)
+}
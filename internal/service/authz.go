@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+)
+
+// authorize checks that the principal of ctx is granted the scope.
+// Calls without a principal come from internal callers, such as Kafka commands, and are allowed.
+func (s *Service) authorize(ctx context.Context, scope string) error {
+	principal := requestctx.Principal(ctx)
+	if principal == nil || principal.HasScope(scope) {
+		return nil
+	}
+	s.log.With("actor", principal.Name, "scope", scope).Warn("Access denied")
+	return fmt.Errorf("%w: %s required", models.ErrForbidden, scope)
+}
+
+// actor returns the name of the principal of ctx for logging, prefixed for API keys and certificates.
+func actor(ctx context.Context) string {
+	principal := requestctx.Principal(ctx)
+	switch {
+	case principal == nil:
+		return models.ActorSystem
+	case principal.Type == models.PrincipalAPIKey:
+		return "api_key:" + principal.Name
+	case principal.Type == models.PrincipalCertificate:
+		return "cert:" + principal.Name
+	default:
+		return principal.Name
+	}
+}
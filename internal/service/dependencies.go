@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"github.com/ezhdanovskiy/companies/internal/kafka"
 	"github.com/ezhdanovskiy/companies/internal/models"
 )
 
@@ -12,10 +14,55 @@ type Repository interface {
 	UpdateCompany(ctx context.Context, companyPatch *models.CompanyPatch) (affected int64, err error)
 	DeleteCompany(ctx context.Context, companyUUID string) (affected int64, err error)
 	GetCompany(ctx context.Context, companyUUID string) (*models.Company, error)
+	GetCompanyForUpdate(ctx context.Context, companyUUID string) (*models.Company, error)
+	GetDeletedCompanyForUpdate(ctx context.Context, companyUUID string) (*models.Company, error)
+	RestoreCompany(ctx context.Context, companyUUID string) (affected int64, err error)
+	PurgeCompanies(ctx context.Context, deletedBefore time.Time, limit int) ([]*models.Company, error)
+	ListCompanies(ctx context.Context, filter *models.CompanyFilter) (*models.CompanyList, error)
+
+	CreateCompanyVersion(ctx context.Context, version *models.CompanyVersion) error
+	ListCompanyVersions(ctx context.Context, filter *models.VersionFilter) ([]*models.CompanyVersion, error)
+	GetCompanyVersion(ctx context.Context, companyUUID string, version int) (*models.CompanyVersion, error)
+	GetCompanyVersionAsOf(ctx context.Context, companyUUID string, asOf time.Time) (*models.CompanyVersion, error)
+
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+
+	CreateOutboxMessage(ctx context.Context, m *models.OutboxMessage) error
+	LockOutboxMessages(ctx context.Context, limit int) ([]*models.OutboxMessage, error)
+	DeleteOutboxMessages(ctx context.Context, ids ...int64) error
+	PostponeOutboxMessage(ctx context.Context, id int64, nextAttemptAt time.Time, reason string) error
+
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	SetUserDisabled(ctx context.Context, username string, disabled bool) (affected int64, err error)
+	GetUser(ctx context.Context, id string) (*models.User, error)
+
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id string) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, username string) error
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti, userID string) (bool, error)
+
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) (affected int64, err error)
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time, precision time.Duration) error
+
+	CreateAuditRecord(ctx context.Context, record *models.AuditRecord) error
+	ListAuditRecords(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditRecord, error)
+
+	CreateIdempotencyKey(ctx context.Context, req *models.IdempotentRequest) (created bool, err error)
+	GetIdempotencyKey(ctx context.Context, principal, key string) (*models.IdempotentRequest, error)
+	LockIdempotencyKey(ctx context.Context, req *models.IdempotentRequest) (locked bool, err error)
+	SaveIdempotentResponse(ctx context.Context, req *models.IdempotentRequest, resp *models.IdempotentResponse) error
+	DeleteIdempotencyKey(ctx context.Context, req *models.IdempotentRequest) error
 }
 
 type Producer interface {
-	Publish(ctx context.Context, messages ...[]byte) error
+	Publish(ctx context.Context, messages ...*kafka.Message) error
 }
 
 //go:generate mockgen -destination=./mocks/repository_mock.go -package=mocks . Repository
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/kafka"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Event types.
+const (
+	EventCompanyCreated  = "com.companies.company.created"
+	EventCompanyUpdated  = "com.companies.company.updated"
+	EventCompanyDeleted  = "com.companies.company.deleted"
+	EventCompanyRestored = "com.companies.company.restored"
+	EventCompanyPurged   = "com.companies.company.purged"
+)
+
+// Event formats of the CloudEvents Kafka protocol binding.
+const (
+	// EventFormatStructured puts the whole Event into the message body.
+	EventFormatStructured = "structured"
+	// EventFormatBinary puts Event.Data into the message body and the other attributes into ce_* headers.
+	EventFormatBinary = "binary"
+)
+
+const (
+	cloudEventsSpecVersion   = "1.0"
+	cloudEventsContentType   = "application/cloudevents+json"
+	cloudEventsHeaderPrefix  = "ce_"
+	eventDataContentType     = "application/json"
+	defaultEventSource       = "/companies"
+	eventSchemaVersion       = "2" // version of Event.Data, bump it on incompatible changes
+	eventCorrelationIDHeader = cloudEventsHeaderPrefix + "correlationid"
+)
+
+// EventsConfig contains parameters of the emitted events.
+type EventsConfig struct {
+	Format string // structured | binary, empty means structured
+	Source string
+}
+
+// Event is a CloudEvents 1.0 envelope of a company change.
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// CompanyChange is the data of company events. Before is empty for created and restored companies,
+// After is empty for deleted and purged ones.
+type CompanyChange struct {
+	Before  *models.Company `json:"before,omitempty"`
+	After   *models.Company `json:"after,omitempty"`
+	Changes []FieldChange   `json:"changes,omitempty"`
+}
+
+// FieldChange describes a changed field of a company.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// diffCompanies lists the fields that differ between two states of a company.
+func diffCompanies(before, after *models.Company) []FieldChange {
+	var changes []FieldChange
+	add := func(field string, from, to interface{}) {
+		if from != to {
+			changes = append(changes, FieldChange{Field: field, Old: from, New: to})
+		}
+	}
+
+	add("Name", before.Name, after.Name)
+	add("Description", before.Description, after.Description)
+	add("EmployeesAmount", before.EmployeesAmount, after.EmployeesAmount)
+	add("Registered", before.Registered, after.Registered)
+	add("Type", before.Type, after.Type)
+
+	return changes
+}
+
+// enqueue stores the event in the outbox keyed by the company ID, so all events of a company land in one partition.
+// It must be called in the transaction of the change the event describes.
+func (s *Service) enqueue(ctx context.Context, eventType, companyID string, data interface{}) error {
+	m, err := s.newEventMessage(ctx, eventType, companyID, data)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateOutboxMessage(ctx, m); err != nil {
+		return err
+	}
+	s.log.With("key", m.Key, "headers", m.Headers, "message", string(m.Payload)).Debug("Event enqueued")
+
+	return nil
+}
+
+func (s *Service) newEventMessage(ctx context.Context, eventType, companyID string, data interface{}) (*models.OutboxMessage, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	correlationID := requestctx.CorrelationID(ctx)
+	if correlationID == "" {
+		correlationID = uuid.New().String()
+	}
+
+	source := s.events.Source
+	if source == "" {
+		source = defaultEventSource
+	}
+
+	ev := &Event{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              uuid.New().String(),
+		Source:          source,
+		Type:            eventType,
+		Subject:         companyID,
+		Time:            s.now().UTC().Format(time.RFC3339Nano),
+		DataContentType: eventDataContentType,
+		CorrelationID:   correlationID,
+		Data:            body,
+	}
+
+	m := &models.OutboxMessage{
+		Key: companyID,
+		Headers: map[string]string{
+			kafka.HeaderEventType:     ev.Type,
+			kafka.HeaderSchemaVersion: eventSchemaVersion,
+			kafka.HeaderCorrelationID: correlationID,
+			kafka.HeaderTimestamp:     ev.Time,
+		},
+	}
+	// The W3C trace context of the request is stored with the message to be continued by consumers.
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(m.Headers))
+
+	if s.events.Format == EventFormatBinary {
+		m.Headers[kafka.HeaderContentType] = ev.DataContentType
+		m.Headers[cloudEventsHeaderPrefix+"specversion"] = ev.SpecVersion
+		m.Headers[cloudEventsHeaderPrefix+"id"] = ev.ID
+		m.Headers[cloudEventsHeaderPrefix+"source"] = ev.Source
+		m.Headers[cloudEventsHeaderPrefix+"type"] = ev.Type
+		m.Headers[cloudEventsHeaderPrefix+"subject"] = ev.Subject
+		m.Headers[cloudEventsHeaderPrefix+"time"] = ev.Time
+		m.Headers[eventCorrelationIDHeader] = ev.CorrelationID
+		m.Payload = body
+		return m, nil
+	}
+
+	m.Headers[kafka.HeaderContentType] = cloudEventsContentType
+	m.Payload, err = json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/kafka"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var eventTime = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestNewEventMessage_Structured(t *testing.T) {
+	svc := newEventsTestService(t, EventFormatStructured)
+	reqCtx := requestctx.WithCorrelationID(context.Background(), "request-id")
+
+	m, err := svc.newEventMessage(reqCtx, EventCompanyCreated, "uuid1", &models.Company{ID: "uuid1", Name: "Name1"})
+	require.NoError(t, err)
+	assert.Equal(t, "uuid1", m.Key)
+	assert.Equal(t, cloudEventsContentType, m.Headers[kafka.HeaderContentType])
+	assert.NotContains(t, m.Headers, "ce_type")
+
+	var ev Event
+	require.NoError(t, json.Unmarshal(m.Payload, &ev))
+	assert.Equal(t, "1.0", ev.SpecVersion)
+	assert.NotEmpty(t, ev.ID)
+	assert.Equal(t, "/test", ev.Source)
+	assert.Equal(t, "com.companies.company.created", ev.Type)
+	assert.Equal(t, "uuid1", ev.Subject)
+	assert.Equal(t, "2023-01-02T03:04:05Z", ev.Time)
+	assert.Equal(t, "application/json", ev.DataContentType)
+	assert.Equal(t, "request-id", ev.CorrelationID)
+	assert.JSONEq(t, `{"ID":"uuid1","Name":"Name1","Description":"","EmployeesAmount":0,"Registered":false,"Type":"","Version":0}`,
+		string(ev.Data))
+}
+
+func TestNewEventMessage_Binary(t *testing.T) {
+	svc := newEventsTestService(t, EventFormatBinary)
+
+	m, err := svc.newEventMessage(context.Background(), EventCompanyDeleted, "uuid1", "uuid1")
+	require.NoError(t, err)
+	assert.Equal(t, "uuid1", m.Key)
+	assert.Equal(t, `"uuid1"`, string(m.Payload))
+
+	assert.Equal(t, "application/json", m.Headers[kafka.HeaderContentType])
+	assert.Equal(t, "1.0", m.Headers["ce_specversion"])
+	assert.NotEmpty(t, m.Headers["ce_id"])
+	assert.Equal(t, "/test", m.Headers["ce_source"])
+	assert.Equal(t, "com.companies.company.deleted", m.Headers["ce_type"])
+	assert.Equal(t, "uuid1", m.Headers["ce_subject"])
+	assert.Equal(t, "2023-01-02T03:04:05Z", m.Headers["ce_time"])
+	assert.NotEmpty(t, m.Headers["ce_correlationid"])
+	assert.Equal(t, m.Headers["ce_correlationid"], m.Headers[kafka.HeaderCorrelationID])
+}
+
+func TestNewEventMessage_TraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	svc := newEventsTestService(t, EventFormatStructured)
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	reqCtx := propagation.TraceContext{}.Extract(context.Background(),
+		propagation.MapCarrier{"traceparent": traceParent})
+
+	m, err := svc.newEventMessage(reqCtx, EventCompanyCreated, "uuid1", &models.Company{ID: "uuid1"})
+	require.NoError(t, err)
+	assert.Equal(t, traceParent, m.Headers["traceparent"])
+}
+
+func TestNewEventMessage_DefaultSource(t *testing.T) {
+	svc := newEventsTestService(t, EventFormatBinary)
+	svc.events.Source = ""
+
+	m, err := svc.newEventMessage(context.Background(), EventCompanyDeleted, "uuid1", "uuid1")
+	require.NoError(t, err)
+	assert.Equal(t, "/companies", m.Headers["ce_source"])
+}
+
+func TestNewService_UnknownEventFormat(t *testing.T) {
+	_, err := NewService(nil, nil, &EventsConfig{Format: "structred"}, &AuthConfig{}, &IdempotencyConfig{})
+	require.Error(t, err)
+}
+
+func newEventsTestService(t *testing.T, format string) *Service {
+	svc, err := NewService(nil, nil, &EventsConfig{Format: format, Source: "/test"}, &AuthConfig{}, &IdempotencyConfig{})
+	require.NoError(t, err)
+	svc.now = func() time.Time { return eventTime }
+	return svc
+}
+
+func TestDiffCompanies(t *testing.T) {
+	before := &models.Company{ID: "uuid1", Name: "Name1", EmployeesAmount: 10, Registered: true, Type: "NonProfit"}
+	after := &models.Company{ID: "uuid1", Name: "Name1", Description: "Descr", EmployeesAmount: 20, Registered: true, Type: "Cooperative"}
+
+	assert.Equal(t, []FieldChange{
+		{Field: "Description", Old: "", New: "Descr"},
+		{Field: "EmployeesAmount", Old: 10, New: 20},
+		{Field: "Type", Old: "NonProfit", New: "Cooperative"},
+	}, diffCompanies(before, after))
+}
+
+func TestDiffCompanies_NoChanges(t *testing.T) {
+	c := &models.Company{ID: "uuid1", Name: "Name1"}
+	assert.Empty(t, diffCompanies(c, c))
+}
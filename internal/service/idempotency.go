@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/ezhdanovskiy/companies/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// IdempotencyConfig contains parameters of requests made with idempotency keys.
+type IdempotencyConfig struct {
+	KeyTTL      time.Duration // responses are replayed for this long
+	LockTimeout time.Duration // a request in progress longer is considered failed, a retry takes its key over
+}
+
+// BeginIdempotentRequest reserves the idempotency key of the principal for the request with the fingerprint.
+// It returns the stored request whose response should be replayed,
+// or the request without a response that should be handled and then completed while it holds the key.
+// A key used for another request fails with ErrIdempotencyKeyReused,
+// a key of a request that isn't completed yet fails with ErrIdempotentRequestInProgress
+// unless the request has been in progress longer than LockTimeout, then the retry takes the key over.
+func (s *Service) BeginIdempotentRequest(ctx context.Context, key, fingerprint string) (_ *models.IdempotentRequest, err error) {
+	s.log.With("key", key, "actor", actor(ctx)).Debug("Service.BeginIdempotentRequest")
+	ctx, span := s.startSpan(ctx, "Service.BeginIdempotentRequest", attribute.String("idempotency.key", key))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	principal := principalKey(ctx)
+	now := s.now()
+	req := &models.IdempotentRequest{
+		Principal:   principal,
+		Key:         key,
+		Fingerprint: fingerprint,
+		// The lock identifies the holder of the key, the database keeps it to microseconds.
+		LockedUntil: now.Add(s.idempotency.LockTimeout).Truncate(time.Microsecond),
+		ExpiresAt:   now.Add(s.idempotency.KeyTTL),
+	}
+	created, err := s.repo.CreateIdempotencyKey(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		return req, nil
+	}
+
+	stored, err := s.repo.GetIdempotencyKey(ctx, principal, key)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case stored == nil:
+		// The key is released by a failed request in the meantime, the client may retry.
+		return nil, models.ErrIdempotentRequestInProgress
+	case stored.Fingerprint != fingerprint:
+		return nil, models.ErrIdempotencyKeyReused
+	case stored.Response == nil && stored.LockedUntil.After(now):
+		return nil, models.ErrIdempotentRequestInProgress
+	case stored.Response == nil:
+		// The request has failed without releasing the key, e.g. the process has crashed.
+		locked, err := s.repo.LockIdempotencyKey(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if !locked {
+			return nil, models.ErrIdempotentRequestInProgress
+		}
+		return req, nil
+	}
+
+	return stored, nil
+}
+
+// CompleteIdempotentRequest stores the response of the request begun by BeginIdempotentRequest.
+// Server errors aren't stored, the key is released so the request can be retried.
+// The key is released as well if the response can't be stored.
+// Nothing is changed if a retry has taken the key over in the meantime, it fails with ErrIdempotencyKeyTakenOver.
+// It isn't interrupted by cancellation of ctx, otherwise the key would be left in progress when the client is gone.
+func (s *Service) CompleteIdempotentRequest(
+	ctx context.Context, req *models.IdempotentRequest, resp *models.IdempotentResponse,
+) (err error) {
+	s.log.With("key", req.Key, "status_code", resp.StatusCode, "actor", actor(ctx)).Debug("Service.CompleteIdempotentRequest")
+	ctx, span := s.startSpan(requestctx.WithPrincipal(context.Background(), requestctx.Principal(ctx)),
+		"Service.CompleteIdempotentRequest", attribute.String("idempotency.key", req.Key))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return s.repo.DeleteIdempotencyKey(ctx, req)
+	}
+	if err = s.repo.SaveIdempotentResponse(ctx, req, resp); err != nil {
+		if errors.Is(err, models.ErrIdempotencyKeyTakenOver) {
+			return err
+		}
+		if delErr := s.repo.DeleteIdempotencyKey(ctx, req); delErr != nil {
+			s.log.With("key", req.Key, "error", delErr).Error("Failed to release idempotency key")
+		}
+		return err
+	}
+	return nil
+}
+
+// principalKey identifies the principal of ctx, idempotency keys of different principals don't clash.
+func principalKey(ctx context.Context) string {
+	principal := requestctx.Principal(ctx)
+	if principal == nil {
+		return models.ActorSystem
+	}
+	return principal.Type + ":" + principal.ID
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_BeginIdempotentRequest(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	now := time.Now()
+	ts.svc.now = func() time.Time { return now }
+	userCtx := requestctx.WithPrincipal(context.Background(), &models.Principal{Type: models.PrincipalUser, ID: "user-id"})
+
+	req := &models.IdempotentRequest{
+		Principal:   models.PrincipalUser + ":user-id",
+		Key:         "key",
+		Fingerprint: "fingerprint",
+		LockedUntil: now.Add(time.Minute).Truncate(time.Microsecond),
+		ExpiresAt:   now.Add(time.Hour),
+	}
+	ts.mockRepo.EXPECT().CreateIdempotencyKey(userCtx, req).Return(true, nil)
+
+	stored, err := ts.svc.BeginIdempotentRequest(userCtx, "key", "fingerprint")
+	require.NoError(t, err)
+	assert.Equal(t, req, stored)
+	assert.Nil(t, stored.Response) // The request is handled
+}
+
+func TestService_BeginIdempotentRequest_Used(t *testing.T) {
+	completed := &models.IdempotentRequest{
+		Principal:   models.ActorSystem,
+		Key:         "key",
+		Fingerprint: "fingerprint",
+		Response:    &models.IdempotentResponse{StatusCode: http.StatusCreated, Body: []byte("null")},
+	}
+	inProgress := &models.IdempotentRequest{
+		Principal:   models.ActorSystem,
+		Key:         "key",
+		Fingerprint: "fingerprint",
+		LockedUntil: time.Now().Add(time.Minute),
+	}
+
+	tests := []struct {
+		name        string
+		stored      *models.IdempotentRequest
+		fingerprint string
+		wantErr     error
+	}{
+		{name: "completed", stored: completed, fingerprint: "fingerprint"},
+		{name: "other request", stored: completed, fingerprint: "other", wantErr: models.ErrIdempotencyKeyReused},
+		{name: "in progress", stored: inProgress, fingerprint: "fingerprint", wantErr: models.ErrIdempotentRequestInProgress},
+		{name: "released", stored: nil, fingerprint: "fingerprint", wantErr: models.ErrIdempotentRequestInProgress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t)
+			defer ts.Finish()
+
+			ts.mockRepo.EXPECT().CreateIdempotencyKey(ctx, gomock.Any()).
+				Return(false, nil)
+			ts.mockRepo.EXPECT().GetIdempotencyKey(ctx, models.ActorSystem, "key").
+				Return(tt.stored, nil)
+
+			stored, err := ts.svc.BeginIdempotentRequest(ctx, "key", tt.fingerprint)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.stored, stored)
+		})
+	}
+}
+
+func TestService_BeginIdempotentRequest_TakeOver(t *testing.T) {
+	stale := &models.IdempotentRequest{
+		Principal:   models.ActorSystem,
+		Key:         "key",
+		Fingerprint: "fingerprint",
+		LockedUntil: time.Now().Add(-time.Minute),
+	}
+
+	tests := []struct {
+		name    string
+		locked  bool
+		wantErr error
+	}{
+		{name: "taken over", locked: true},
+		{name: "taken over by another retry", locked: false, wantErr: models.ErrIdempotentRequestInProgress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t)
+			defer ts.Finish()
+
+			ts.mockRepo.EXPECT().CreateIdempotencyKey(ctx, gomock.Any()).
+				Return(false, nil)
+			ts.mockRepo.EXPECT().GetIdempotencyKey(ctx, models.ActorSystem, "key").
+				Return(stale, nil)
+			ts.mockRepo.EXPECT().LockIdempotencyKey(ctx, gomock.Any()).
+				Return(tt.locked, nil)
+
+			stored, err := ts.svc.BeginIdempotentRequest(ctx, "key", "fingerprint")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Nil(t, stored.Response) // The request is handled again
+			assert.True(t, stored.LockedUntil.After(stale.LockedUntil))
+		})
+	}
+}
+
+func TestService_CompleteIdempotentRequest(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	resp := &models.IdempotentResponse{StatusCode: http.StatusConflict, ContentType: "application/json", Body: []byte("{}")}
+	ts.mockRepo.EXPECT().SaveIdempotentResponse(gomock.Any(), testIdempotentRequest, resp).
+		DoAndReturn(func(ctx context.Context, _ *models.IdempotentRequest, _ *models.IdempotentResponse) error {
+			return ctx.Err()
+		})
+
+	requestCtx, cancel := context.WithCancel(context.Background())
+	cancel() // The client is gone
+	err := ts.svc.CompleteIdempotentRequest(requestCtx, testIdempotentRequest, resp)
+	require.NoError(t, err)
+}
+
+func TestService_CompleteIdempotentRequest_ServerError(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	ts.mockRepo.EXPECT().DeleteIdempotencyKey(gomock.Any(), testIdempotentRequest).
+		Return(nil)
+
+	err := ts.svc.CompleteIdempotentRequest(ctx, testIdempotentRequest,
+		&models.IdempotentResponse{StatusCode: http.StatusInternalServerError})
+	require.NoError(t, err) // The key is released for retries
+}
+
+func TestService_CompleteIdempotentRequest_SaveFailed(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	resp := &models.IdempotentResponse{StatusCode: http.StatusCreated}
+	ts.mockRepo.EXPECT().SaveIdempotentResponse(gomock.Any(), testIdempotentRequest, resp).
+		Return(errors.New("db error"))
+	ts.mockRepo.EXPECT().DeleteIdempotencyKey(gomock.Any(), testIdempotentRequest).
+		Return(nil)
+
+	err := ts.svc.CompleteIdempotentRequest(ctx, testIdempotentRequest, resp)
+	require.Error(t, err) // The key is released, so a retry isn't answered with 409
+}
+
+func TestService_CompleteIdempotentRequest_TakenOver(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	resp := &models.IdempotentResponse{StatusCode: http.StatusCreated}
+	ts.mockRepo.EXPECT().SaveIdempotentResponse(gomock.Any(), testIdempotentRequest, resp).
+		Return(models.ErrIdempotencyKeyTakenOver)
+
+	err := ts.svc.CompleteIdempotentRequest(ctx, testIdempotentRequest, resp)
+	assert.ErrorIs(t, err, models.ErrIdempotencyKeyTakenOver) // The key of the retry isn't released
+}
+
+var testIdempotentRequest = &models.IdempotentRequest{
+	Principal:   models.ActorSystem,
+	Key:         "key",
+	Fingerprint: "fingerprint",
+	LockedUntil: time.Date(2023, 1, 2, 3, 4, 5, 6000, time.UTC),
+}
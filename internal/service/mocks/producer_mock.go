@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	kafka "github.com/ezhdanovskiy/companies/internal/kafka"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,7 +36,7 @@ func (m *MockProducer) EXPECT() *MockProducerMockRecorder {
 }
 
 // Publish mocks base method.
-func (m *MockProducer) Publish(arg0 context.Context, arg1 ...[]byte) error {
+func (m *MockProducer) Publish(arg0 context.Context, arg1 ...*kafka.Message) error {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{arg0}
 	for _, a := range arg1 {
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/kafka"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"go.uber.org/zap"
+)
+
+// RelayConfig contains parameters of OutboxRelay.
+type RelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	BackoffMin   time.Duration
+	BackoffMax   time.Duration
+}
+
+// OutboxRelay delivers messages from the outbox to Kafka in the order they were stored.
+// A message is deleted from the outbox only after it was published. With a synchronous producer
+// it means acknowledged by the brokers, so delivery is at-least-once. An asynchronous producer only
+// queues the messages, their delivery is as reliable as the producer's handling of failures.
+type OutboxRelay struct {
+	log      *zap.SugaredLogger
+	repo     Repository
+	producer Producer
+	cfg      RelayConfig
+	now      func() time.Time
+}
+
+// NewOutboxRelay fails if the poll interval or the batch size isn't positive.
+func NewOutboxRelay(log *zap.SugaredLogger, repo Repository, producer Producer, cfg *RelayConfig) (*OutboxRelay, error) {
+	if cfg.PollInterval <= 0 {
+		return nil, fmt.Errorf("outbox poll interval must be positive, got %v", cfg.PollInterval)
+	}
+	if cfg.BatchSize <= 0 {
+		return nil, fmt.Errorf("outbox batch size must be positive, got %d", cfg.BatchSize)
+	}
+
+	return &OutboxRelay{
+		log:      log,
+		repo:     repo,
+		producer: producer,
+		cfg:      *cfg,
+		now:      time.Now,
+	}, nil
+}
+
+// Run drains the outbox until ctx is canceled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			relayed, err := r.relayBatch(ctx)
+			if err != nil {
+				r.log.With("error", err).Warn("Failed to relay outbox")
+				break
+			}
+			if relayed < r.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relayBatch publishes the oldest batch of outbox messages and returns how many of them were delivered.
+func (r *OutboxRelay) relayBatch(ctx context.Context) (relayed int, err error) {
+	err = r.repo.RunInTx(ctx, func(ctx context.Context) error {
+		messages, err := r.repo.LockOutboxMessages(ctx, r.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		// Only the head of the outbox is ever postponed. Messages behind it wait to keep the order.
+		head := messages[0]
+		if head.NextAttemptAt.After(r.now()) {
+			return nil
+		}
+
+		ids := make([]int64, 0, len(messages))
+		kafkaMessages := make([]*kafka.Message, 0, len(messages))
+		for _, m := range messages {
+			ids = append(ids, m.ID)
+			kafkaMessages = append(kafkaMessages, &kafka.Message{
+				Key:     m.Key,
+				Headers: m.Headers,
+				Body:    m.Payload,
+			})
+		}
+
+		if err := r.producer.Publish(ctx, kafkaMessages...); err != nil {
+			nextAttemptAt := r.now().Add(r.backoff(head.Attempts))
+			r.log.With("id", head.ID, "attempts", head.Attempts+1, "next_attempt_at", nextAttemptAt, "error", err).
+				Warn("Failed to publish outbox messages")
+			return r.repo.PostponeOutboxMessage(ctx, head.ID, nextAttemptAt, err.Error())
+		}
+
+		if err := r.repo.DeleteOutboxMessages(ctx, ids...); err != nil {
+			return err
+		}
+		relayed = len(messages)
+		r.log.With("amount", relayed).Debug("Outbox messages relayed")
+
+		return nil
+	})
+
+	return relayed, err
+}
+
+// Requeue appends messages an asynchronous producer failed to deliver to the outbox, so they are relayed again.
+// Their order relative to the messages stored in the meantime isn't kept. It matches kafka.ProducerConfig.OnDeliveryFailure.
+func (r *OutboxRelay) Requeue(messages []*kafka.Message, reason error) {
+	log := r.log.With("amount", len(messages), "reason", reason)
+
+	err := r.repo.RunInTx(context.Background(), func(ctx context.Context) error {
+		for _, m := range messages {
+			err := r.repo.CreateOutboxMessage(ctx, &models.OutboxMessage{
+				Key:     m.Key,
+				Headers: m.Headers,
+				Payload: m.Body,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.With("error", err).Error("Failed to requeue undelivered messages, they are lost")
+		return
+	}
+	log.Warn("Undelivered messages requeued to the outbox")
+}
+
+// backoff returns the delay before the next delivery attempt growing exponentially from BackoffMin up to BackoffMax.
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	delay := r.cfg.BackoffMin
+	for i := 0; i < attempts && delay < r.cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > r.cfg.BackoffMax {
+		delay = r.cfg.BackoffMax
+	}
+	return delay
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/kafka"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var relayNow = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestOutboxRelay_RelayBatch(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+	relay := ts.newRelay()
+
+	headers := map[string]string{kafka.HeaderEventType: EventCompanyCreated}
+	messages := []*models.OutboxMessage{
+		{ID: 1, Key: "uuid1", Headers: headers, Payload: []byte("first"), NextAttemptAt: relayNow},
+		{ID: 2, Key: "uuid2", Headers: headers, Payload: []byte("second"), NextAttemptAt: relayNow},
+	}
+
+	gomock.InOrder(
+		ts.mockRepo.EXPECT().LockOutboxMessages(gomock.Any(), 10).
+			Return(messages, nil),
+		ts.mockProducer.EXPECT().Publish(gomock.Any(),
+			&kafka.Message{Key: "uuid1", Headers: headers, Body: []byte("first")},
+			&kafka.Message{Key: "uuid2", Headers: headers, Body: []byte("second")},
+		).Return(nil),
+		ts.mockRepo.EXPECT().DeleteOutboxMessages(gomock.Any(), int64(1), int64(2)).
+			Return(nil),
+	)
+
+	relayed, err := relay.relayBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, relayed)
+}
+
+func TestOutboxRelay_RelayBatch_Empty(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+	relay := ts.newRelay()
+
+	ts.mockRepo.EXPECT().LockOutboxMessages(gomock.Any(), 10).
+		Return(nil, nil)
+
+	relayed, err := relay.relayBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, relayed)
+}
+
+func TestOutboxRelay_RelayBatch_HeadPostponed(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+	relay := ts.newRelay()
+
+	messages := []*models.OutboxMessage{
+		{ID: 1, Payload: []byte("first"), Attempts: 1, NextAttemptAt: relayNow.Add(time.Second)},
+		{ID: 2, Payload: []byte("second"), NextAttemptAt: relayNow},
+	}
+
+	ts.mockRepo.EXPECT().LockOutboxMessages(gomock.Any(), 10).
+		Return(messages, nil)
+
+	relayed, err := relay.relayBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, relayed)
+}
+
+func TestOutboxRelay_RelayBatch_PublishError(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+	relay := ts.newRelay()
+
+	messages := []*models.OutboxMessage{
+		{ID: 1, Payload: []byte("first"), Attempts: 2, NextAttemptAt: relayNow},
+	}
+
+	ts.mockRepo.EXPECT().LockOutboxMessages(gomock.Any(), 10).
+		Return(messages, nil)
+	ts.mockProducer.EXPECT().Publish(gomock.Any(), &kafka.Message{Body: []byte("first")}).
+		Return(errors.New("publish error"))
+	ts.mockRepo.EXPECT().PostponeOutboxMessage(gomock.Any(), int64(1), relayNow.Add(4*time.Second), "publish error").
+		Return(nil)
+
+	relayed, err := relay.relayBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, relayed)
+}
+
+func TestOutboxRelay_RelayBatch_LockError(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+	relay := ts.newRelay()
+
+	expectedErr := errors.New("LockOutboxMessagesError")
+	ts.mockRepo.EXPECT().LockOutboxMessages(gomock.Any(), 10).
+		Return(nil, expectedErr)
+
+	_, err := relay.relayBatch(ctx)
+	require.Error(t, err)
+	assert.Equal(t, expectedErr, err)
+}
+
+func TestOutboxRelay_Requeue(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+	relay := ts.newRelay()
+
+	headers := map[string]string{kafka.HeaderEventType: EventCompanyCreated}
+	gomock.InOrder(
+		ts.mockRepo.EXPECT().CreateOutboxMessage(gomock.Any(),
+			&models.OutboxMessage{Key: "uuid1", Headers: headers, Payload: []byte("first")}).
+			Return(nil),
+		ts.mockRepo.EXPECT().CreateOutboxMessage(gomock.Any(),
+			&models.OutboxMessage{Key: "uuid2", Headers: headers, Payload: []byte("second")}).
+			Return(nil),
+	)
+
+	relay.Requeue([]*kafka.Message{
+		{Partition: 1, Offset: 2, Key: "uuid1", Headers: headers, Body: []byte("first")},
+		{Partition: 1, Offset: 3, Key: "uuid2", Headers: headers, Body: []byte("second")},
+	}, errors.New("delivery error"))
+}
+
+func TestOutboxRelay_Backoff(t *testing.T) {
+	relay := &OutboxRelay{cfg: RelayConfig{BackoffMin: time.Second, BackoffMax: 10 * time.Second}}
+
+	assert.Equal(t, time.Second, relay.backoff(0))
+	assert.Equal(t, 2*time.Second, relay.backoff(1))
+	assert.Equal(t, 8*time.Second, relay.backoff(3))
+	assert.Equal(t, 10*time.Second, relay.backoff(4))
+	assert.Equal(t, 10*time.Second, relay.backoff(100))
+}
+
+func TestNewOutboxRelay_InvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]*RelayConfig{
+		"zero poll interval": {PollInterval: 0, BatchSize: 10},
+		"zero batch size":    {PollInterval: time.Second, BatchSize: 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ts := newTestService(t)
+			defer ts.Finish()
+
+			_, err := NewOutboxRelay(ts.log, ts.mockRepo, ts.mockProducer, cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func (ts *TestService) newRelay() *OutboxRelay {
+	relay, err := NewOutboxRelay(ts.log, ts.mockRepo, ts.mockProducer, &RelayConfig{
+		PollInterval: time.Second,
+		BatchSize:    10,
+		BackoffMin:   time.Second,
+		BackoffMax:   time.Minute,
+	})
+	require.NoError(ts.t, err)
+	relay.now = func() time.Time { return relayNow }
+	return relay
+}
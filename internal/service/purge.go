@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/metrics"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// PurgeConfig contains parameters of Purger.
+type PurgeConfig struct {
+	Interval  time.Duration
+	Retention time.Duration // deleted companies are kept for this long
+	BatchSize int
+}
+
+// Purger permanently deletes companies deleted longer than the retention ago.
+type Purger struct {
+	log *zap.SugaredLogger
+	svc *Service
+	cfg PurgeConfig
+	now func() time.Time
+}
+
+// NewPurger fails if the interval or the batch size isn't positive.
+func NewPurger(log *zap.SugaredLogger, svc *Service, cfg *PurgeConfig) (*Purger, error) {
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("purge interval must be positive, got %v", cfg.Interval)
+	}
+	if cfg.BatchSize <= 0 {
+		return nil, fmt.Errorf("purge batch size must be positive, got %d", cfg.BatchSize)
+	}
+
+	return &Purger{
+		log: log,
+		svc: svc,
+		cfg: *cfg,
+		now: time.Now,
+	}, nil
+}
+
+// Run purges expired companies every Interval until ctx is canceled.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			purged, err := p.svc.PurgeCompanies(ctx, p.now().Add(-p.cfg.Retention), p.cfg.BatchSize)
+			if err != nil {
+				p.log.With("error", err).Warn("Failed to purge companies")
+				break
+			}
+			if purged > 0 {
+				p.log.With("purged", purged).Info("Deleted companies purged")
+			}
+			if purged < p.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeCompanies permanently deletes up to limit companies deleted before the time
+// and publishes the purged events. Their versions and audit records are kept.
+func (s *Service) PurgeCompanies(ctx context.Context, deletedBefore time.Time, limit int) (purged int, err error) {
+	s.log.With("deleted_before", deletedBefore, "limit", limit).Debug("Service.PurgeCompanies")
+	ctx, span := s.startSpan(ctx, "Service.PurgeCompanies", attribute.Int("limit", limit))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var companies []*models.Company
+	err = s.repo.RunInTx(ctx, func(ctx context.Context) error {
+		companies, err = s.repo.PurgeCompanies(ctx, deletedBefore, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, company := range companies {
+			err := s.enqueue(ctx, EventCompanyPurged, company.ID, &CompanyChange{
+				Before: company,
+			})
+			if err != nil {
+				return err
+			}
+
+			if err := s.audit(ctx, models.AuditActionPurged, company.ID, company, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, company := range companies {
+		metrics.CompanyMutations.WithLabelValues(metrics.ActionPurged, company.Type).Inc()
+	}
+	return len(companies), nil
+}
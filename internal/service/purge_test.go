@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/kafka"
+	"github.com/ezhdanovskiy/companies/internal/metrics"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_PurgeCompanies(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	deletedBefore := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	purged := []*models.Company{
+		{ID: "uuid1", Type: "NonProfit"},
+		{ID: "uuid2", Type: "NonProfit"},
+	}
+	counter := metrics.CompanyMutations.WithLabelValues(metrics.ActionPurged, "NonProfit")
+	before := testutil.ToFloat64(counter)
+
+	ts.mockRepo.EXPECT().PurgeCompanies(ctx, deletedBefore, 10).
+		Return(purged, nil)
+	for _, company := range purged {
+		company := company
+		ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, m *models.OutboxMessage) error {
+				assert.Equal(t, company.ID, m.Key)
+				assert.Equal(t, EventCompanyPurged, m.Headers[kafka.HeaderEventType])
+				data := decodeEventData(t, m)
+				assert.Equal(t, company, data.Before)
+				assert.Nil(t, data.After)
+				return nil
+			})
+		ts.mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, record *models.AuditRecord) error {
+				assert.Equal(t, company.ID, record.CompanyID)
+				assert.Equal(t, models.AuditActionPurged, record.Action)
+				assert.Equal(t, models.ActorSystem, record.Actor)
+				return nil
+			})
+	}
+
+	n, err := ts.svc.PurgeCompanies(ctx, deletedBefore, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, before+2, testutil.ToFloat64(counter))
+}
+
+func TestService_PurgeCompanies_OutboxError(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	expectedErr := errors.New("CreateOutboxMessageError")
+
+	ts.mockRepo.EXPECT().PurgeCompanies(ctx, gomock.Any(), 10).
+		Return([]*models.Company{{ID: "uuid1"}}, nil)
+	ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+		Return(expectedErr)
+
+	n, err := ts.svc.PurgeCompanies(ctx, time.Now(), 10)
+	require.Error(t, err) // The companies aren't purged without their events
+	assert.Equal(t, expectedErr, err)
+	assert.Zero(t, n)
+}
+
+func TestNewPurger_InvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]*PurgeConfig{
+		"zero interval":   {Interval: 0, Retention: time.Hour, BatchSize: 100},
+		"zero batch size": {Interval: time.Hour, Retention: time.Hour, BatchSize: 0},
+	} {
+		_, err := NewPurger(nil, nil, cfg)
+		assert.Error(t, err, name)
+	}
+
+	_, err := NewPurger(nil, nil, &PurgeConfig{Interval: time.Hour, Retention: time.Hour, BatchSize: 100})
+	require.NoError(t, err)
+}
@@ -2,106 +2,270 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"time"
 
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/metrics"
 	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 type Service struct {
-	log      *zap.SugaredLogger
-	repo     Repository
-	producer Producer
+	log         *zap.SugaredLogger
+	repo        Repository
+	events      EventsConfig
+	auth        AuthConfig
+	idempotency IdempotencyConfig
+	now         func() time.Time
+	tracer      trace.Tracer
 }
 
-func NewService(log *zap.SugaredLogger, repo Repository, producer Producer) *Service {
-	return &Service{
-		log:      log,
-		repo:     repo,
-		producer: producer,
+// NewService fails if the event format is unknown.
+func NewService(
+	log *zap.SugaredLogger, repo Repository, events *EventsConfig, authCfg *AuthConfig, idempotency *IdempotencyConfig,
+) (*Service, error) {
+	switch events.Format {
+	case EventFormatStructured, EventFormatBinary, "":
+	default:
+		return nil, fmt.Errorf("unknown event format %q", events.Format)
 	}
-}
 
-type Event struct {
-	Message string
-	Body    interface{}
+	return &Service{
+		log:         log,
+		repo:        repo,
+		events:      *events,
+		auth:        *authCfg,
+		idempotency: *idempotency,
+		now:         time.Now,
+		tracer:      otel.Tracer(tracerName),
+	}, nil
 }
 
-func (s *Service) publish(ctx context.Context, ev *Event) error {
-	message, err := json.Marshal(ev)
-	if err != nil {
+func (s *Service) CreateCompany(ctx context.Context, company *models.Company) (err error) {
+	s.log.With("id", company.ID, "actor", actor(ctx)).Debug("Service.CreateCompany")
+	ctx, span := s.startSpan(ctx, "Service.CreateCompany", attribute.String("company.id", company.ID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.authorize(ctx, auth.ScopeCompaniesWrite); err != nil {
 		return err
 	}
 
-	if err := s.producer.Publish(ctx, message); err != nil {
+	err = s.repo.RunInTx(ctx, func(ctx context.Context) error {
+		err := s.repo.CreateCompany(ctx, company)
+		if err != nil {
+			return err
+		}
+
+		if err := s.addVersion(ctx, company, false); err != nil {
+			return err
+		}
+
+		err = s.enqueue(ctx, EventCompanyCreated, company.ID, &CompanyChange{
+			After: company,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.audit(ctx, models.AuditActionCreated, company.ID, nil, company)
+	})
+	if err != nil {
 		return err
 	}
-	s.log.With("message", string(message)).Debug("Event published")
 
+	metrics.CompanyMutations.WithLabelValues(metrics.ActionCreated, company.Type).Inc()
 	return nil
 }
 
-func (s *Service) CreateCompany(ctx context.Context, company *models.Company) error {
-	s.log.With("id", company.ID).Debug("Service.CreateCompany")
-	err := s.repo.CreateCompany(ctx, company)
-	if err != nil {
+func (s *Service) UpdateCompany(ctx context.Context, companyPatch *models.CompanyPatch) (err error) {
+	s.log.With("id", companyPatch.ID, "actor", actor(ctx)).Debug("Service.UpdateCompany")
+	ctx, span := s.startSpan(ctx, "Service.UpdateCompany", attribute.String("company.id", companyPatch.ID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.authorize(ctx, auth.ScopeCompaniesWrite); err != nil {
 		return err
 	}
 
-	err = s.publish(ctx, &Event{
-		Message: "Company created",
-		Body:    company,
+	var after *models.Company
+	err = s.repo.RunInTx(ctx, func(ctx context.Context) error {
+		after, err = s.applyPatch(ctx, companyPatch, models.AuditActionUpdated)
+		return err
 	})
 	if err != nil {
-		s.log.With("error", err).Warn("Failed to publish message")
+		return err
 	}
 
+	metrics.CompanyMutations.WithLabelValues(metrics.ActionUpdated, after.Type).Inc()
 	return nil
 }
 
-func (s *Service) UpdateCompany(ctx context.Context, companyPatch *models.CompanyPatch) error {
-	s.log.With("id", companyPatch.ID).Debug("Service.UpdateCompany")
+// applyPatch updates the company, stores its new version, the update event and the audit record with the action.
+// It must be called in a transaction.
+func (s *Service) applyPatch(ctx context.Context, companyPatch *models.CompanyPatch, auditAction string) (*models.Company, error) {
+	before, err := s.repo.GetCompanyForUpdate(ctx, companyPatch.ID)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, models.ErrCompanyNotFound
+	}
+	if companyPatch.Version != nil && *companyPatch.Version != before.Version {
+		return nil, models.ErrVersionMismatch
+	}
+
 	affected, err := s.repo.UpdateCompany(ctx, companyPatch)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if affected == 0 {
-		return models.ErrCompanyNotFound
+		return nil, models.ErrCompanyNotFound
 	}
 
-	err = s.publish(ctx, &Event{
-		Message: "Company updated",
-		Body:    companyPatch,
+	after, err := s.repo.GetCompany(ctx, companyPatch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.addVersion(ctx, after, false); err != nil {
+		return nil, err
+	}
+
+	err = s.enqueue(ctx, EventCompanyUpdated, companyPatch.ID, &CompanyChange{
+		Before:  before,
+		After:   after,
+		Changes: diffCompanies(before, after),
 	})
 	if err != nil {
-		s.log.With("error", err).Warn("Failed to publish message")
+		return nil, err
 	}
 
-	return nil
+	if err := s.audit(ctx, auditAction, companyPatch.ID, before, after); err != nil {
+		return nil, err
+	}
+
+	return after, nil
 }
 
-func (s *Service) DeleteCompany(ctx context.Context, uuid string) error {
-	s.log.With("uuid", uuid).Debug("Service.DeleteCompany")
-	affected, err := s.repo.DeleteCompany(ctx, uuid)
+// DeleteCompany marks the company deleted. If the version is set, it must be the current version of the company.
+func (s *Service) DeleteCompany(ctx context.Context, uuid string, version *int) (err error) {
+	s.log.With("uuid", uuid, "actor", actor(ctx)).Debug("Service.DeleteCompany")
+	ctx, span := s.startSpan(ctx, "Service.DeleteCompany", attribute.String("company.id", uuid))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.authorize(ctx, auth.ScopeCompaniesDelete); err != nil {
+		return err
+	}
+
+	var companyType string
+	err = s.repo.RunInTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetCompanyForUpdate(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return models.ErrCompanyNotFound
+		}
+		if version != nil && *version != before.Version {
+			return models.ErrVersionMismatch
+		}
+		companyType = before.Type
+
+		affected, err := s.repo.DeleteCompany(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrCompanyNotFound
+		}
+
+		if err := s.addVersion(ctx, before, true); err != nil {
+			return err
+		}
+
+		err = s.enqueue(ctx, EventCompanyDeleted, uuid, &CompanyChange{
+			Before: before,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.audit(ctx, models.AuditActionDeleted, uuid, before, nil)
+	})
 	if err != nil {
 		return err
 	}
-	if affected == 0 {
-		return models.ErrCompanyNotFound
+
+	metrics.CompanyMutations.WithLabelValues(metrics.ActionDeleted, companyType).Inc()
+	return nil
+}
+
+// RestoreCompany undeletes a deleted company that isn't purged yet. It is added as the next version unchanged.
+func (s *Service) RestoreCompany(ctx context.Context, uuid string) (_ *models.Company, err error) {
+	s.log.With("uuid", uuid, "actor", actor(ctx)).Debug("Service.RestoreCompany")
+	ctx, span := s.startSpan(ctx, "Service.RestoreCompany", attribute.String("company.id", uuid))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.authorize(ctx, auth.ScopeCompaniesDelete); err != nil {
+		return nil, err
 	}
 
-	err = s.publish(ctx, &Event{
-		Message: "Company deleted",
-		Body:    uuid,
+	var company *models.Company
+	err = s.repo.RunInTx(ctx, func(ctx context.Context) error {
+		company, err = s.repo.GetDeletedCompanyForUpdate(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if company == nil {
+			return models.ErrCompanyNotFound
+		}
+
+		affected, err := s.repo.RestoreCompany(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrCompanyNotFound
+		}
+		company.Version++
+
+		if err := s.addVersion(ctx, company, false); err != nil {
+			return err
+		}
+
+		err = s.enqueue(ctx, EventCompanyRestored, uuid, &CompanyChange{
+			After: company,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.audit(ctx, models.AuditActionRestored, uuid, nil, company)
 	})
 	if err != nil {
-		s.log.With("error", err).Warn("Failed to publish message")
+		return nil, err
 	}
 
-	return nil
+	metrics.CompanyMutations.WithLabelValues(metrics.ActionRestored, company.Type).Inc()
+	return company, nil
 }
 
-func (s *Service) GetCompany(ctx context.Context, uuid string) (*models.Company, error) {
+func (s *Service) GetCompany(ctx context.Context, uuid string) (_ *models.Company, err error) {
 	s.log.With("uuid", uuid).Debug("Service.GetCompany")
+	ctx, span := s.startSpan(ctx, "Service.GetCompany", attribute.String("company.id", uuid))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	return s.repo.GetCompany(ctx, uuid)
 }
+
+func (s *Service) ListCompanies(ctx context.Context, filter *models.CompanyFilter) (_ *models.CompanyList, err error) {
+	s.log.With("filter", filter).Debug("Service.ListCompanies")
+	ctx, span := s.startSpan(ctx, "Service.ListCompanies")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return s.repo.ListCompanies(ctx, filter)
+}
@@ -2,14 +2,22 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/kafka"
+	"github.com/ezhdanovskiy/companies/internal/metrics"
 	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
 	"github.com/ezhdanovskiy/companies/internal/service/mocks"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -20,22 +28,65 @@ var ctx context.Context
 func TestNewService_CreateCompany(t *testing.T) {
 	ts := newTestService(t)
 	defer ts.Finish()
+	ts.expectTx()
 
 	company := &models.Company{}
 
 	ts.mockRepo.EXPECT().CreateCompany(ctx, company).
 		Return(nil)
+	ts.mockRepo.EXPECT().CreateCompanyVersion(ctx, gomock.Any()).
+		Return(nil)
+
+	ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+		Return(nil)
 
-	ts.mockProducer.EXPECT().Publish(ctx, gomock.Any()).
+	ts.mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).
 		Return(nil)
 
 	err := ts.svc.CreateCompany(ctx, company)
 	require.NoError(t, err)
 }
 
+func TestNewService_CreateCompany_EventKeyAndHeaders(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	ts.svc.now = func() time.Time { return now }
+
+	company := &models.Company{ID: "test-uuid"}
+	reqCtx := requestctx.WithCorrelationID(context.Background(), "request-id")
+
+	ts.mockRepo.EXPECT().CreateCompany(reqCtx, company).
+		Return(nil)
+	ts.mockRepo.EXPECT().CreateCompanyVersion(reqCtx, gomock.Any()).
+		Return(nil)
+
+	ts.mockRepo.EXPECT().CreateOutboxMessage(reqCtx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, m *models.OutboxMessage) error {
+			assert.Equal(t, "test-uuid", m.Key)
+			assert.Equal(t, map[string]string{
+				kafka.HeaderEventType:     EventCompanyCreated,
+				kafka.HeaderSchemaVersion: "2",
+				kafka.HeaderCorrelationID: "request-id",
+				kafka.HeaderTimestamp:     "2023-01-02T03:04:05Z",
+				kafka.HeaderContentType:   "application/cloudevents+json",
+			}, m.Headers)
+			return nil
+		})
+
+	ts.mockRepo.EXPECT().CreateAuditRecord(reqCtx, gomock.Any()).
+		Return(nil)
+
+	err := ts.svc.CreateCompany(reqCtx, company)
+	require.NoError(t, err)
+}
+
 func TestNewService_CreateCompany_Error(t *testing.T) {
 	ts := newTestService(t)
 	defer ts.Finish()
+	ts.expectTx()
 
 	company := &models.Company{}
 	expectedErr := errors.New("CreateCompanyError")
@@ -48,34 +99,100 @@ func TestNewService_CreateCompany_Error(t *testing.T) {
 	assert.Equal(t, expectedErr, err)
 }
 
-func TestNewService_CreateCompany_PublishError(t *testing.T) {
+func TestNewService_CreateCompany_OutboxError(t *testing.T) {
 	ts := newTestService(t)
 	defer ts.Finish()
+	ts.expectTx()
 
 	company := &models.Company{}
+	expectedErr := errors.New("CreateOutboxMessageError")
 
 	ts.mockRepo.EXPECT().CreateCompany(ctx, company).
 		Return(nil)
+	ts.mockRepo.EXPECT().CreateCompanyVersion(ctx, gomock.Any()).
+		Return(nil)
 
-	ts.mockProducer.EXPECT().Publish(ctx, gomock.Any()).
-		Return(errors.New("publish error"))
+	ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+		Return(expectedErr)
 
 	err := ts.svc.CreateCompany(ctx, company)
-	require.NoError(t, err) // Should not return error even if publish fails
+	require.Error(t, err) // The change is rolled back if the event can't be stored
+	assert.Equal(t, expectedErr, err)
+}
+
+func TestNewService_CreateCompany_Metrics(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	company := &models.Company{Type: "Cooperative"}
+	counter := metrics.CompanyMutations.WithLabelValues(metrics.ActionCreated, company.Type)
+	expectedErr := errors.New("CreateOutboxMessageError")
+
+	ts.mockRepo.EXPECT().CreateCompany(ctx, company).
+		Return(nil).Times(2)
+	ts.mockRepo.EXPECT().CreateCompanyVersion(ctx, gomock.Any()).
+		Return(nil).Times(2)
+	gomock.InOrder(
+		ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+			Return(expectedErr),
+		ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+			Return(nil),
+	)
+	ts.mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).
+		Return(nil)
+
+	before := testutil.ToFloat64(counter)
+
+	err := ts.svc.CreateCompany(ctx, company)
+	require.Error(t, err)
+	assert.Equal(t, before, testutil.ToFloat64(counter)) // Rolled back changes are not counted
+
+	err = ts.svc.CreateCompany(ctx, company)
+	require.NoError(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
 }
 
 func TestNewService_UpdateCompany(t *testing.T) {
 	ts := newTestService(t)
 	defer ts.Finish()
+	ts.expectTx()
 
-	company := &models.CompanyPatch{}
+	name := "New Name"
+	company := &models.CompanyPatch{ID: "test-uuid", Name: &name}
+	before := &models.Company{ID: "test-uuid", Name: "Old Name", EmployeesAmount: 10}
+	after := &models.Company{ID: "test-uuid", Name: "New Name", EmployeesAmount: 10}
 	affected := int64(1)
 
-	ts.mockRepo.EXPECT().UpdateCompany(ctx, company).
-		Return(affected, nil)
-
-	ts.mockProducer.EXPECT().Publish(ctx, gomock.Any()).
-		Return(nil)
+	gomock.InOrder(
+		ts.mockRepo.EXPECT().GetCompanyForUpdate(ctx, company.ID).
+			Return(before, nil),
+		ts.mockRepo.EXPECT().UpdateCompany(ctx, company).
+			Return(affected, nil),
+		ts.mockRepo.EXPECT().GetCompany(ctx, company.ID).
+			Return(after, nil),
+		ts.mockRepo.EXPECT().CreateCompanyVersion(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *models.CompanyVersion) error {
+				assert.Equal(t, after, v.Company)
+				assert.False(t, v.Deleted)
+				return nil
+			}),
+		ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, m *models.OutboxMessage) error {
+				data := decodeEventData(t, m)
+				assert.Equal(t, before, data.Before)
+				assert.Equal(t, after, data.After)
+				assert.Equal(t, []FieldChange{{Field: "Name", Old: "Old Name", New: "New Name"}}, data.Changes)
+				return nil
+			}),
+		ts.mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, record *models.AuditRecord) error {
+				assert.Equal(t, models.AuditActionUpdated, record.Action)
+				assert.JSONEq(t, mustMarshal(t, before), string(record.Before))
+				assert.JSONEq(t, mustMarshal(t, after), string(record.After))
+				return nil
+			}),
+	)
 
 	err := ts.svc.UpdateCompany(ctx, company)
 	require.NoError(t, err)
@@ -84,11 +201,15 @@ func TestNewService_UpdateCompany(t *testing.T) {
 func TestNewService_UpdateCompany_Error(t *testing.T) {
 	ts := newTestService(t)
 	defer ts.Finish()
+	ts.expectTx()
 
 	company := &models.CompanyPatch{}
 	affected := int64(0)
 	expectedErr := errors.New("CreateCompanyError")
 
+	ts.mockRepo.EXPECT().GetCompanyForUpdate(ctx, company.ID).
+		Return(&models.Company{}, nil)
+
 	ts.mockRepo.EXPECT().UpdateCompany(ctx, company).
 		Return(affected, expectedErr)
 
@@ -97,67 +218,177 @@ func TestNewService_UpdateCompany_Error(t *testing.T) {
 	assert.Equal(t, expectedErr, err)
 }
 
+func TestNewService_UpdateCompany_GetError(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	company := &models.CompanyPatch{}
+	expectedErr := errors.New("GetCompanyForUpdateError")
+
+	ts.mockRepo.EXPECT().GetCompanyForUpdate(ctx, company.ID).
+		Return(nil, expectedErr)
+
+	err := ts.svc.UpdateCompany(ctx, company)
+	require.Error(t, err)
+	assert.Equal(t, expectedErr, err)
+}
+
 func TestNewService_UpdateCompany_NotFound(t *testing.T) {
 	ts := newTestService(t)
 	defer ts.Finish()
+	ts.expectTx()
 
 	company := &models.CompanyPatch{}
-	affected := int64(0)
 
-	ts.mockRepo.EXPECT().UpdateCompany(ctx, company).
-		Return(affected, nil)
+	ts.mockRepo.EXPECT().GetCompanyForUpdate(ctx, company.ID).
+		Return(nil, nil)
 
 	err := ts.svc.UpdateCompany(ctx, company)
 	require.Error(t, err)
 	assert.Equal(t, models.ErrCompanyNotFound, err)
 }
 
-func TestNewService_UpdateCompany_PublishError(t *testing.T) {
+func TestNewService_UpdateCompany_VersionMismatch(t *testing.T) {
 	ts := newTestService(t)
 	defer ts.Finish()
+	ts.expectTx()
+
+	version := 1
+	company := &models.CompanyPatch{ID: "test-uuid", Version: &version}
+
+	ts.mockRepo.EXPECT().GetCompanyForUpdate(ctx, company.ID).
+		Return(&models.Company{ID: company.ID, Version: 2}, nil)
+
+	err := ts.svc.UpdateCompany(ctx, company)
+	assert.ErrorIs(t, err, models.ErrVersionMismatch)
+}
+
+func TestNewService_UpdateCompany_OutboxError(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
 
 	company := &models.CompanyPatch{}
 	affected := int64(1)
+	expectedErr := errors.New("CreateOutboxMessageError")
+
+	ts.mockRepo.EXPECT().GetCompanyForUpdate(ctx, company.ID).
+		Return(&models.Company{}, nil)
 
 	ts.mockRepo.EXPECT().UpdateCompany(ctx, company).
 		Return(affected, nil)
 
-	ts.mockProducer.EXPECT().Publish(ctx, gomock.Any()).
-		Return(errors.New("publish error"))
+	ts.mockRepo.EXPECT().GetCompany(ctx, company.ID).
+		Return(&models.Company{}, nil)
+
+	ts.mockRepo.EXPECT().CreateCompanyVersion(ctx, gomock.Any()).
+		Return(nil)
+
+	ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+		Return(expectedErr)
 
 	err := ts.svc.UpdateCompany(ctx, company)
-	require.NoError(t, err) // Should not return error even if publish fails
+	require.Error(t, err) // The change is rolled back if the event can't be stored
+	assert.Equal(t, expectedErr, err)
 }
 
 func TestNewService_DeleteCompany(t *testing.T) {
 	ts := newTestService(t)
 	defer ts.Finish()
+	ts.expectTx()
 
 	uuid := "test-uuid"
+	before := &models.Company{ID: uuid, Name: "Name"}
 	affected := int64(1)
 
-	ts.mockRepo.EXPECT().DeleteCompany(ctx, uuid).
-		Return(affected, nil)
+	gomock.InOrder(
+		ts.mockRepo.EXPECT().GetCompanyForUpdate(ctx, uuid).
+			Return(before, nil),
+		ts.mockRepo.EXPECT().DeleteCompany(ctx, uuid).
+			Return(affected, nil),
+		ts.mockRepo.EXPECT().CreateCompanyVersion(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *models.CompanyVersion) error {
+				assert.Equal(t, before, v.Company) // The last state is kept
+				assert.True(t, v.Deleted)
+				return nil
+			}),
+		ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, m *models.OutboxMessage) error {
+				data := decodeEventData(t, m)
+				assert.Equal(t, before, data.Before)
+				assert.Nil(t, data.After)
+				assert.Empty(t, data.Changes)
+				return nil
+			}),
+		ts.mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, record *models.AuditRecord) error {
+				assert.Equal(t, models.AuditActionDeleted, record.Action)
+				assert.JSONEq(t, mustMarshal(t, before), string(record.Before))
+				assert.Nil(t, record.After)
+				return nil
+			}),
+	)
+
+	err := ts.svc.DeleteCompany(ctx, uuid, nil)
+	require.NoError(t, err)
+}
 
-	ts.mockProducer.EXPECT().Publish(ctx, gomock.Any()).
+func TestNewService_DeleteCompany_Forbidden(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	editorCtx := requestctx.WithPrincipal(context.Background(), &models.Principal{
+		Name:   "editor",
+		Scopes: []string{auth.ScopeCompaniesRead, auth.ScopeCompaniesWrite},
+	})
+
+	err := ts.svc.DeleteCompany(editorCtx, "test-uuid", nil)
+	require.Error(t, err) // The repository isn't touched
+	assert.ErrorIs(t, err, models.ErrForbidden)
+}
+
+func TestNewService_DeleteCompany_Authorized(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	managerCtx := requestctx.WithPrincipal(context.Background(), &models.Principal{
+		Name:   "manager",
+		Scopes: []string{auth.ScopeCompaniesRead, auth.ScopeCompaniesWrite, auth.ScopeCompaniesDelete},
+	})
+
+	ts.mockRepo.EXPECT().GetCompanyForUpdate(managerCtx, "test-uuid").
+		Return(&models.Company{ID: "test-uuid"}, nil)
+	ts.mockRepo.EXPECT().DeleteCompany(managerCtx, "test-uuid").
+		Return(int64(1), nil)
+	ts.mockRepo.EXPECT().CreateCompanyVersion(managerCtx, gomock.Any()).
+		Return(nil)
+	ts.mockRepo.EXPECT().CreateOutboxMessage(managerCtx, gomock.Any()).
+		Return(nil)
+	ts.mockRepo.EXPECT().CreateAuditRecord(managerCtx, gomock.Any()).
 		Return(nil)
 
-	err := ts.svc.DeleteCompany(ctx, uuid)
+	err := ts.svc.DeleteCompany(managerCtx, "test-uuid", nil)
 	require.NoError(t, err)
 }
 
 func TestNewService_DeleteCompany_Error(t *testing.T) {
 	ts := newTestService(t)
 	defer ts.Finish()
+	ts.expectTx()
 
 	uuid := "test-uuid"
 	affected := int64(0)
 	expectedErr := errors.New("DeleteCompanyError")
 
+	ts.mockRepo.EXPECT().GetCompanyForUpdate(ctx, uuid).
+		Return(&models.Company{ID: uuid}, nil)
+
 	ts.mockRepo.EXPECT().DeleteCompany(ctx, uuid).
 		Return(affected, expectedErr)
 
-	err := ts.svc.DeleteCompany(ctx, uuid)
+	err := ts.svc.DeleteCompany(ctx, uuid, nil)
 	require.Error(t, err)
 	assert.Equal(t, expectedErr, err)
 }
@@ -165,33 +396,106 @@ func TestNewService_DeleteCompany_Error(t *testing.T) {
 func TestNewService_DeleteCompany_NotFound(t *testing.T) {
 	ts := newTestService(t)
 	defer ts.Finish()
+	ts.expectTx()
 
 	uuid := "test-uuid"
-	affected := int64(0)
 
-	ts.mockRepo.EXPECT().DeleteCompany(ctx, uuid).
-		Return(affected, nil)
+	ts.mockRepo.EXPECT().GetCompanyForUpdate(ctx, uuid).
+		Return(nil, nil)
 
-	err := ts.svc.DeleteCompany(ctx, uuid)
+	err := ts.svc.DeleteCompany(ctx, uuid, nil)
 	require.Error(t, err)
 	assert.Equal(t, models.ErrCompanyNotFound, err)
 }
 
-func TestNewService_DeleteCompany_PublishError(t *testing.T) {
+func TestNewService_DeleteCompany_VersionMismatch(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	uuid := "test-uuid"
+	version := 1
+
+	ts.mockRepo.EXPECT().GetCompanyForUpdate(ctx, uuid).
+		Return(&models.Company{ID: uuid, Version: 2}, nil)
+
+	err := ts.svc.DeleteCompany(ctx, uuid, &version)
+	assert.ErrorIs(t, err, models.ErrVersionMismatch)
+}
+
+func TestNewService_DeleteCompany_OutboxError(t *testing.T) {
 	ts := newTestService(t)
 	defer ts.Finish()
+	ts.expectTx()
 
 	uuid := "test-uuid"
 	affected := int64(1)
+	expectedErr := errors.New("CreateOutboxMessageError")
+
+	ts.mockRepo.EXPECT().GetCompanyForUpdate(ctx, uuid).
+		Return(&models.Company{ID: uuid}, nil)
 
 	ts.mockRepo.EXPECT().DeleteCompany(ctx, uuid).
 		Return(affected, nil)
 
-	ts.mockProducer.EXPECT().Publish(ctx, gomock.Any()).
-		Return(errors.New("publish error"))
+	ts.mockRepo.EXPECT().CreateCompanyVersion(ctx, gomock.Any()).
+		Return(nil)
+
+	ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+		Return(expectedErr)
+
+	err := ts.svc.DeleteCompany(ctx, uuid, nil)
+	require.Error(t, err) // The change is rolled back if the event can't be stored
+	assert.Equal(t, expectedErr, err)
+}
+
+func TestNewService_RestoreCompany(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	company := &models.Company{ID: "test-uuid", Name: "Name", Type: "Cooperative", Version: 2}
+
+	gomock.InOrder(
+		ts.mockRepo.EXPECT().GetDeletedCompanyForUpdate(ctx, "test-uuid").
+			Return(company, nil),
+		ts.mockRepo.EXPECT().RestoreCompany(ctx, "test-uuid").
+			Return(int64(1), nil),
+		ts.mockRepo.EXPECT().CreateCompanyVersion(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *models.CompanyVersion) error {
+				assert.Equal(t, company, v.Company)
+				assert.False(t, v.Deleted)
+				return nil
+			}),
+		ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, m *models.OutboxMessage) error {
+				assert.Equal(t, EventCompanyRestored, m.Headers[kafka.HeaderEventType])
+				assert.Equal(t, company, decodeEventData(t, m).After)
+				return nil
+			}),
+		ts.mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, record *models.AuditRecord) error {
+				assert.Equal(t, models.AuditActionRestored, record.Action)
+				return nil
+			}),
+	)
+
+	restored, err := ts.svc.RestoreCompany(ctx, "test-uuid")
+	require.NoError(t, err)
+	assert.Equal(t, company, restored)
+	assert.Equal(t, 3, restored.Version)
+}
+
+func TestNewService_RestoreCompany_NotDeleted(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
 
-	err := ts.svc.DeleteCompany(ctx, uuid)
-	require.NoError(t, err) // Should not return error even if publish fails
+	ts.mockRepo.EXPECT().GetDeletedCompanyForUpdate(ctx, "test-uuid").
+		Return(nil, nil)
+
+	_, err := ts.svc.RestoreCompany(ctx, "test-uuid")
+	assert.ErrorIs(t, err, models.ErrCompanyNotFound)
 }
 
 func TestNewService_GetCompany(t *testing.T) {
@@ -246,6 +550,40 @@ func TestNewService_GetCompany_NotFound(t *testing.T) {
 	assert.Nil(t, company)
 }
 
+func TestNewService_ListCompanies(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	filter := &models.CompanyFilter{Limit: 10}
+	expectedList := &models.CompanyList{
+		Companies:  []*models.Company{{ID: "test-uuid", Name: "Test Company"}},
+		NextCursor: "next-cursor",
+	}
+
+	ts.mockRepo.EXPECT().ListCompanies(ctx, filter).
+		Return(expectedList, nil)
+
+	list, err := ts.svc.ListCompanies(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, expectedList, list)
+}
+
+func TestNewService_ListCompanies_Error(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	filter := &models.CompanyFilter{Limit: 10}
+	expectedErr := errors.New("ListCompaniesError")
+
+	ts.mockRepo.EXPECT().ListCompanies(ctx, filter).
+		Return(nil, expectedErr)
+
+	list, err := ts.svc.ListCompanies(ctx, filter)
+	require.Error(t, err)
+	assert.Nil(t, list)
+	assert.Equal(t, expectedErr, err)
+}
+
 // TestService ---------------------------------------------------------------------------------------------------------
 type TestService struct {
 	t            *testing.T
@@ -273,11 +611,48 @@ func newTestService(t *testing.T) TestService {
 		ts.log = zap.NewNop().Sugar()
 	}
 
-	ts.svc = NewService(ts.log, ts.mockRepo, ts.mockProducer)
+	svc, err := NewService(ts.log, ts.mockRepo, &EventsConfig{Format: EventFormatStructured, Source: "/test"},
+		&AuthConfig{RefreshTokenTTL: time.Hour}, &IdempotencyConfig{KeyTTL: time.Hour, LockTimeout: time.Minute})
+	require.NoError(t, err)
+	ts.svc = svc
+	ts.svc.tracer = passThroughTracer{}
 
 	return ts
 }
 
+// passThroughTracer doesn't modify the context, so the repository mocks get the same context as the service.
+type passThroughTracer struct {
+	trace.Tracer
+}
+
+func (passThroughTracer) Start(ctx context.Context, _ string, _ ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return ctx, trace.SpanFromContext(ctx)
+}
+
+// decodeEventData extracts CompanyChange from a structured CloudEvents message.
+func decodeEventData(t *testing.T, m *models.OutboxMessage) *CompanyChange {
+	var ev Event
+	require.NoError(t, json.Unmarshal(m.Payload, &ev))
+
+	var data CompanyChange
+	require.NoError(t, json.Unmarshal(ev.Data, &data))
+	return &data
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
+
+// expectTx makes the mocked repository run a transaction function in place.
+func (ts *TestService) expectTx() {
+	ts.mockRepo.EXPECT().RunInTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+}
+
 func (ts *TestService) Finish() {
 	ts.mockCtrl.Finish()
 }
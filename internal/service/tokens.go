@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/ezhdanovskiy/companies/internal/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// AuthConfig contains parameters of the issued tokens.
+type AuthConfig struct {
+	RefreshTokenTTL time.Duration
+}
+
+// RefreshTokens exchanges a refresh token for new tokens. The refresh token can be used only once,
+// presenting a used or revoked token revokes all refresh tokens issued by rotation from the same login.
+func (s *Service) RefreshTokens(ctx context.Context, refreshToken string) (_ *models.Tokens, err error) {
+	s.log.Debug("Service.RefreshTokens")
+	ctx, span := s.startSpan(ctx, "Service.RefreshTokens")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var tokens *models.Tokens
+	var reused bool
+	err = s.repo.RunInTx(ctx, func(ctx context.Context) error {
+		stored, err := s.repo.GetRefreshTokenForUpdate(ctx, auth.HashRefreshToken(refreshToken))
+		if err != nil {
+			return err
+		}
+		if stored == nil {
+			return models.ErrInvalidRefreshToken
+		}
+
+		if stored.UsedAt != nil || stored.RevokedAt != nil {
+			// The token may have been stolen, so the legitimate client loses its session too.
+			// The revocation is committed, the error is returned after the transaction.
+			reused = true
+			return s.repo.RevokeRefreshTokenFamily(ctx, stored.FamilyID)
+		}
+
+		if !stored.ExpiresAt.After(s.now()) {
+			return models.ErrInvalidRefreshToken
+		}
+
+		user, err := s.repo.GetUser(ctx, stored.UserID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return models.ErrInvalidRefreshToken
+		}
+		if user.DisabledAt != nil {
+			return models.ErrUserDisabled
+		}
+
+		if err := s.repo.MarkRefreshTokenUsed(ctx, stored.ID); err != nil {
+			return err
+		}
+
+		tokens, err = s.issueTokens(ctx, user, stored.FamilyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		s.log.Warn("Refresh token reused, token family revoked")
+		return nil, models.ErrRefreshTokenReused
+	}
+
+	return tokens, nil
+}
+
+// Logout revokes the access token and the refresh tokens of the session, if refreshToken is given.
+func (s *Service) Logout(ctx context.Context, access *models.AccessToken, refreshToken string) (err error) {
+	s.log.With("jti", access.ID).Debug("Service.Logout")
+	ctx, span := s.startSpan(ctx, "Service.Logout")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	// The access token is revoked even if the refresh token turns out to be invalid.
+	if access.ID != "" {
+		if err := s.repo.RevokeToken(ctx, access.ID, access.ExpiresAt); err != nil {
+			return err
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	return s.repo.RunInTx(ctx, func(ctx context.Context) error {
+		stored, err := s.repo.GetRefreshTokenForUpdate(ctx, auth.HashRefreshToken(refreshToken))
+		if err != nil {
+			return err
+		}
+		// Refresh tokens of other users are treated as unknown, so they can't be probed or revoked.
+		if principal := requestctx.Principal(ctx); stored == nil || principal == nil || stored.UserID != principal.ID {
+			return models.ErrInvalidRefreshToken
+		}
+
+		return s.repo.RevokeRefreshTokenFamily(ctx, stored.FamilyID)
+	})
+}
+
+// RevokeToken adds the access token ID to the revocation list. Tokens live no longer than auth.AccessTokenTTL,
+// so the revocation is kept for this time.
+func (s *Service) RevokeToken(ctx context.Context, jti string) (err error) {
+	s.log.With("jti", jti, "actor", actor(ctx)).Debug("Service.RevokeToken")
+	ctx, span := s.startSpan(ctx, "Service.RevokeToken", attribute.String("jti", jti))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.authorize(ctx, auth.ScopeUsersManage); err != nil {
+		return err
+	}
+
+	return s.repo.RevokeToken(ctx, jti, s.now().Add(auth.AccessTokenTTL))
+}
+
+// IsTokenRevoked checks whether the access token ID is in the revocation list or the user of the token is disabled,
+// so disabling a user takes effect on tokens already issued.
+func (s *Service) IsTokenRevoked(ctx context.Context, jti, userID string) (bool, error) {
+	return s.repo.IsTokenRevoked(ctx, jti, userID)
+}
+
+// issueTokens generates an access token and a refresh token of the family for the user.
+func (s *Service) issueTokens(ctx context.Context, user *models.User, familyID string) (*models.Tokens, error) {
+	access, err := auth.GenerateAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &models.RefreshToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: s.now().Add(s.auth.RefreshTokenTTL),
+	}
+	if err := s.repo.CreateRefreshToken(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	return &models.Tokens{
+		Access:           access,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/requestctx"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RefreshTokens(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	stored := &models.RefreshToken{
+		ID:        "token-id",
+		UserID:    "user-id",
+		FamilyID:  "family-id",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	user := &models.User{ID: "user-id", Username: "user"}
+
+	gomock.InOrder(
+		ts.mockRepo.EXPECT().GetRefreshTokenForUpdate(ctx, auth.HashRefreshToken("refresh-token")).
+			Return(stored, nil),
+		ts.mockRepo.EXPECT().GetUser(ctx, "user-id").
+			Return(user, nil),
+		ts.mockRepo.EXPECT().MarkRefreshTokenUsed(ctx, "token-id").
+			Return(nil),
+		ts.mockRepo.EXPECT().CreateRefreshToken(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, t2 *models.RefreshToken) error {
+				assert.NotEqual(t, "token-id", t2.ID)
+				assert.Equal(t, "family-id", t2.FamilyID) // Rotation keeps the family
+				assert.Equal(t, "user-id", t2.UserID)
+				return nil
+			}),
+	)
+
+	tokens, err := ts.svc.RefreshTokens(ctx, "refresh-token")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.Access.Token)
+	assert.NotEqual(t, "refresh-token", tokens.RefreshToken)
+}
+
+func TestService_RefreshTokens_Reused(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	usedAt := time.Now().Add(-time.Minute)
+	stored := &models.RefreshToken{
+		ID:        "token-id",
+		UserID:    "user-id",
+		FamilyID:  "family-id",
+		ExpiresAt: time.Now().Add(time.Hour),
+		UsedAt:    &usedAt,
+	}
+
+	gomock.InOrder(
+		ts.mockRepo.EXPECT().GetRefreshTokenForUpdate(ctx, auth.HashRefreshToken("refresh-token")).
+			Return(stored, nil),
+		ts.mockRepo.EXPECT().RevokeRefreshTokenFamily(ctx, "family-id").
+			Return(nil),
+	)
+
+	_, err := ts.svc.RefreshTokens(ctx, "refresh-token")
+	assert.ErrorIs(t, err, models.ErrRefreshTokenReused)
+}
+
+func TestService_RefreshTokens_Expired(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	ts.mockRepo.EXPECT().GetRefreshTokenForUpdate(ctx, auth.HashRefreshToken("refresh-token")).
+		Return(&models.RefreshToken{ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+
+	_, err := ts.svc.RefreshTokens(ctx, "refresh-token")
+	assert.ErrorIs(t, err, models.ErrInvalidRefreshToken)
+}
+
+func TestService_RefreshTokens_Unknown(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	ts.mockRepo.EXPECT().GetRefreshTokenForUpdate(ctx, auth.HashRefreshToken("refresh-token")).
+		Return(nil, nil)
+
+	_, err := ts.svc.RefreshTokens(ctx, "refresh-token")
+	assert.ErrorIs(t, err, models.ErrInvalidRefreshToken)
+}
+
+func TestService_RefreshTokens_UserDisabled(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	disabledAt := time.Now()
+	gomock.InOrder(
+		ts.mockRepo.EXPECT().GetRefreshTokenForUpdate(ctx, auth.HashRefreshToken("refresh-token")).
+			Return(&models.RefreshToken{UserID: "user-id", ExpiresAt: time.Now().Add(time.Hour)}, nil),
+		ts.mockRepo.EXPECT().GetUser(ctx, "user-id").
+			Return(&models.User{ID: "user-id", DisabledAt: &disabledAt}, nil),
+	)
+
+	_, err := ts.svc.RefreshTokens(ctx, "refresh-token")
+	assert.ErrorIs(t, err, models.ErrUserDisabled)
+}
+
+func TestService_Logout(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	access := &models.AccessToken{ID: "jti", ExpiresAt: time.Now().Add(time.Hour)}
+	userCtx := requestctx.WithPrincipal(context.Background(), &models.Principal{Type: models.PrincipalUser, ID: "user-id"})
+
+	gomock.InOrder(
+		ts.mockRepo.EXPECT().RevokeToken(userCtx, "jti", access.ExpiresAt).
+			Return(nil),
+		ts.mockRepo.EXPECT().GetRefreshTokenForUpdate(userCtx, auth.HashRefreshToken("refresh-token")).
+			Return(&models.RefreshToken{UserID: "user-id", FamilyID: "family-id"}, nil),
+		ts.mockRepo.EXPECT().RevokeRefreshTokenFamily(userCtx, "family-id").
+			Return(nil),
+	)
+
+	err := ts.svc.Logout(userCtx, access, "refresh-token")
+	require.NoError(t, err)
+}
+
+func TestService_Logout_InvalidRefreshToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored *models.RefreshToken
+	}{
+		{name: "unknown", stored: nil},
+		{name: "of another user", stored: &models.RefreshToken{UserID: "other-user-id", FamilyID: "family-id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t)
+			defer ts.Finish()
+			ts.expectTx()
+
+			access := &models.AccessToken{ID: "jti", ExpiresAt: time.Now().Add(time.Hour)}
+			userCtx := requestctx.WithPrincipal(context.Background(), &models.Principal{Type: models.PrincipalUser, ID: "user-id"})
+
+			// The access token is revoked anyway, the family of another user isn't
+			ts.mockRepo.EXPECT().RevokeToken(userCtx, "jti", access.ExpiresAt).
+				Return(nil)
+			ts.mockRepo.EXPECT().GetRefreshTokenForUpdate(userCtx, auth.HashRefreshToken("refresh-token")).
+				Return(tt.stored, nil)
+
+			err := ts.svc.Logout(userCtx, access, "refresh-token")
+			assert.ErrorIs(t, err, models.ErrInvalidRefreshToken)
+		})
+	}
+}
+
+func TestService_Logout_WithoutRefreshToken(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	access := &models.AccessToken{ID: "jti", ExpiresAt: time.Now().Add(time.Hour)}
+
+	ts.mockRepo.EXPECT().RevokeToken(ctx, "jti", access.ExpiresAt).
+		Return(nil)
+
+	err := ts.svc.Logout(ctx, access, "")
+	require.NoError(t, err)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Login checks the credentials of the user and issues an access token and a refresh token.
+func (s *Service) Login(ctx context.Context, username, password string) (_ *models.Tokens, err error) {
+	s.log.With("username", username).Debug("Service.Login")
+	ctx, span := s.startSpan(ctx, "Service.Login", attribute.String("user.name", username))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	user, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	var hash string
+	if user != nil {
+		hash = user.PasswordHash
+	}
+	ok, err := auth.CheckPassword(hash, password)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, models.ErrInvalidCredentials
+	}
+
+	if user.DisabledAt != nil {
+		return nil, models.ErrUserDisabled
+	}
+
+	return s.issueTokens(ctx, user, uuid.New().String())
+}
+
+// CreateUser creates a user with a hashed password.
+func (s *Service) CreateUser(ctx context.Context, newUser *models.NewUser) (_ *models.User, err error) {
+	s.log.With("username", newUser.Username, "roles", newUser.Roles, "actor", actor(ctx)).Debug("Service.CreateUser")
+	ctx, span := s.startSpan(ctx, "Service.CreateUser", attribute.String("user.name", newUser.Username))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.authorize(ctx, auth.ScopeUsersManage); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetUserByUsername(ctx, newUser.Username)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, models.ErrUserAlreadyExists
+	}
+
+	hash, err := auth.HashPassword(newUser.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		ID:           uuid.New().String(),
+		Username:     newUser.Username,
+		Email:        newUser.Email,
+		PasswordHash: hash,
+		Roles:        newUser.Roles,
+	}
+	if err := s.repo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// DisableUser forbids the user to log in, revokes the refresh tokens of the user
+// and makes the access tokens already issued to the user revoked.
+func (s *Service) DisableUser(ctx context.Context, username string) error {
+	s.log.With("username", username, "actor", actor(ctx)).Debug("Service.DisableUser")
+	return s.setUserDisabled(ctx, username, true)
+}
+
+// EnableUser allows a disabled user to log in again.
+func (s *Service) EnableUser(ctx context.Context, username string) error {
+	s.log.With("username", username, "actor", actor(ctx)).Debug("Service.EnableUser")
+	return s.setUserDisabled(ctx, username, false)
+}
+
+func (s *Service) setUserDisabled(ctx context.Context, username string, disabled bool) (err error) {
+	ctx, span := s.startSpan(ctx, "Service.SetUserDisabled",
+		attribute.String("user.name", username), attribute.Bool("user.disabled", disabled))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.authorize(ctx, auth.ScopeUsersManage); err != nil {
+		return err
+	}
+
+	return s.repo.RunInTx(ctx, func(ctx context.Context) error {
+		affected, err := s.repo.SetUserDisabled(ctx, username, disabled)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrUserNotFound
+		}
+
+		if disabled {
+			return s.repo.RevokeUserRefreshTokens(ctx, username)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Login(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	hash, err := auth.HashPassword("password")
+	require.NoError(t, err)
+	user := &models.User{ID: "user-id", Username: "user", PasswordHash: hash}
+
+	ts.mockRepo.EXPECT().GetUserByUsername(ctx, "user").
+		Return(user, nil)
+
+	var stored *models.RefreshToken
+	ts.mockRepo.EXPECT().CreateRefreshToken(ctx, gomock.Any()).
+		DoAndReturn(func(_ interface{}, t *models.RefreshToken) error {
+			stored = t
+			return nil
+		})
+
+	tokens, err := ts.svc.Login(ctx, "user", "password")
+	require.NoError(t, err)
+
+	claims, err := auth.ParseToken(tokens.Access.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-id", claims.Subject)
+	assert.Empty(t, claims.Scopes)
+
+	require.NotNil(t, stored)
+	assert.Equal(t, "user-id", stored.UserID)
+	assert.NotEmpty(t, stored.FamilyID)
+	assert.Equal(t, auth.HashRefreshToken(tokens.RefreshToken), stored.TokenHash)
+	assert.Equal(t, stored.ExpiresAt, tokens.RefreshExpiresAt)
+}
+
+func TestService_Login_WrongPassword(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	hash, err := auth.HashPassword("password")
+	require.NoError(t, err)
+
+	ts.mockRepo.EXPECT().GetUserByUsername(ctx, "user").
+		Return(&models.User{Username: "user", PasswordHash: hash}, nil)
+
+	_, err = ts.svc.Login(ctx, "user", "wrong")
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+}
+
+func TestService_Login_UnknownUser(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	ts.mockRepo.EXPECT().GetUserByUsername(ctx, "user").
+		Return(nil, nil)
+
+	_, err := ts.svc.Login(ctx, "user", "password")
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+}
+
+func TestService_Login_Disabled(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	hash, err := auth.HashPassword("password")
+	require.NoError(t, err)
+	disabledAt := time.Now()
+
+	ts.mockRepo.EXPECT().GetUserByUsername(ctx, "user").
+		Return(&models.User{Username: "user", PasswordHash: hash, DisabledAt: &disabledAt}, nil)
+
+	_, err = ts.svc.Login(ctx, "user", "password")
+	assert.ErrorIs(t, err, models.ErrUserDisabled)
+}
+
+func TestService_CreateUser(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	newUser := &models.NewUser{Username: "user", Email: "user@example.com", Password: "password", Roles: []string{auth.RoleAdmin}}
+
+	ts.mockRepo.EXPECT().GetUserByUsername(ctx, "user").
+		Return(nil, nil)
+	ts.mockRepo.EXPECT().CreateUser(ctx, gomock.Any()).
+		DoAndReturn(func(_ interface{}, u *models.User) error {
+			assert.NotEmpty(t, u.ID)
+			assert.Equal(t, "user", u.Username)
+			assert.Equal(t, "user@example.com", u.Email)
+			assert.Equal(t, []string{auth.RoleAdmin}, u.Roles)
+			ok, err := auth.CheckPassword(u.PasswordHash, "password")
+			require.NoError(t, err)
+			assert.True(t, ok)
+			return nil
+		})
+
+	user, err := ts.svc.CreateUser(ctx, newUser)
+	require.NoError(t, err)
+	assert.Equal(t, "user", user.Username)
+}
+
+func TestService_CreateUser_AlreadyExists(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+
+	ts.mockRepo.EXPECT().GetUserByUsername(ctx, "user").
+		Return(&models.User{Username: "user"}, nil)
+
+	_, err := ts.svc.CreateUser(ctx, &models.NewUser{Username: "user", Password: "password"})
+	assert.ErrorIs(t, err, models.ErrUserAlreadyExists)
+}
+
+func TestService_DisableUser(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	gomock.InOrder(
+		ts.mockRepo.EXPECT().SetUserDisabled(ctx, "user", true).
+			Return(int64(1), nil),
+		ts.mockRepo.EXPECT().RevokeUserRefreshTokens(ctx, "user").
+			Return(nil),
+	)
+
+	err := ts.svc.DisableUser(ctx, "user")
+	require.NoError(t, err)
+}
+
+func TestService_DisableUser_NotFound(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	ts.mockRepo.EXPECT().SetUserDisabled(ctx, "user", true).
+		Return(int64(0), nil)
+
+	err := ts.svc.DisableUser(ctx, "user")
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+}
+
+func TestService_EnableUser_Error(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	expectedErr := errors.New("SetUserDisabledError")
+	ts.mockRepo.EXPECT().SetUserDisabled(ctx, "user", false).
+		Return(int64(0), expectedErr)
+
+	err := ts.svc.EnableUser(ctx, "user")
+	assert.Equal(t, expectedErr, err)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/auth"
+	"github.com/ezhdanovskiy/companies/internal/metrics"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/ezhdanovskiy/companies/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// addVersion stores the state of the company as its next version.
+// It must be called in the transaction of the change with the company locked.
+func (s *Service) addVersion(ctx context.Context, company *models.Company, deleted bool) error {
+	return s.repo.CreateCompanyVersion(ctx, &models.CompanyVersion{
+		Company:   company,
+		Deleted:   deleted,
+		CreatedAt: s.now(),
+	})
+}
+
+// ListCompanyVersions returns a page of the versions of the company in order.
+func (s *Service) ListCompanyVersions(ctx context.Context, filter *models.VersionFilter) (_ []*models.CompanyVersion, err error) {
+	s.log.With("filter", filter).Debug("Service.ListCompanyVersions")
+	ctx, span := s.startSpan(ctx, "Service.ListCompanyVersions", attribute.String("company.id", filter.CompanyID))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return s.repo.ListCompanyVersions(ctx, filter)
+}
+
+// GetCompanyVersion returns the version of the company or nil if there is no such version.
+func (s *Service) GetCompanyVersion(ctx context.Context, uuid string, version int) (_ *models.CompanyVersion, err error) {
+	s.log.With("uuid", uuid, "version", version).Debug("Service.GetCompanyVersion")
+	ctx, span := s.startSpan(ctx, "Service.GetCompanyVersion",
+		attribute.String("company.id", uuid), attribute.Int("company.version", version))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return s.repo.GetCompanyVersion(ctx, uuid, version)
+}
+
+// GetCompanyAsOf returns the state of the company at the time
+// or nil if the company didn't exist yet or was already deleted.
+func (s *Service) GetCompanyAsOf(ctx context.Context, uuid string, asOf time.Time) (_ *models.Company, err error) {
+	s.log.With("uuid", uuid, "as_of", asOf).Debug("Service.GetCompanyAsOf")
+	ctx, span := s.startSpan(ctx, "Service.GetCompanyAsOf", attribute.String("company.id", uuid))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	version, err := s.repo.GetCompanyVersionAsOf(ctx, uuid, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if version == nil || version.Deleted {
+		return nil, nil
+	}
+
+	return version.Company, nil
+}
+
+// RevertCompany applies the state of the version to the company as its next version.
+// It is published and audited as an update, the audit action is reverted.
+func (s *Service) RevertCompany(ctx context.Context, uuid string, version int) (_ *models.Company, err error) {
+	s.log.With("uuid", uuid, "version", version, "actor", actor(ctx)).Debug("Service.RevertCompany")
+	ctx, span := s.startSpan(ctx, "Service.RevertCompany",
+		attribute.String("company.id", uuid), attribute.Int("company.version", version))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := s.authorize(ctx, auth.ScopeCompaniesWrite); err != nil {
+		return nil, err
+	}
+
+	var after *models.Company
+	err = s.repo.RunInTx(ctx, func(ctx context.Context) error {
+		v, err := s.repo.GetCompanyVersion(ctx, uuid, version)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			return models.ErrVersionNotFound
+		}
+
+		after, err = s.applyPatch(ctx, &models.CompanyPatch{
+			ID:              uuid,
+			Name:            &v.Company.Name,
+			Description:     &v.Company.Description,
+			EmployeesAmount: &v.Company.EmployeesAmount,
+			Registered:      &v.Company.Registered,
+			Type:            &v.Company.Type,
+		}, models.AuditActionReverted)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.CompanyMutations.WithLabelValues(metrics.ActionUpdated, after.Type).Inc()
+	return after, nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ezhdanovskiy/companies/internal/kafka"
+	"github.com/ezhdanovskiy/companies/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_GetCompanyAsOf(t *testing.T) {
+	asOf := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	company := &models.Company{ID: "test-uuid", Name: "Name"}
+
+	tests := []struct {
+		name     string
+		version  *models.CompanyVersion
+		expected *models.Company
+	}{
+		{name: "existed", version: &models.CompanyVersion{Version: 2, Company: company}, expected: company},
+		{name: "not created yet", version: nil, expected: nil},
+		{name: "deleted", version: &models.CompanyVersion{Version: 3, Company: company, Deleted: true}, expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t)
+			defer ts.Finish()
+
+			ts.mockRepo.EXPECT().GetCompanyVersionAsOf(ctx, "test-uuid", asOf).
+				Return(tt.version, nil)
+
+			got, err := ts.svc.GetCompanyAsOf(ctx, "test-uuid", asOf)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestService_RevertCompany(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	current := &models.Company{ID: "test-uuid", Name: "New Name", EmployeesAmount: 20, Type: "Cooperative"}
+	old := &models.Company{ID: "test-uuid", Name: "Old Name", EmployeesAmount: 10, Type: "Cooperative"}
+
+	gomock.InOrder(
+		ts.mockRepo.EXPECT().GetCompanyVersion(ctx, "test-uuid", 1).
+			Return(&models.CompanyVersion{Version: 1, Company: old}, nil),
+		ts.mockRepo.EXPECT().GetCompanyForUpdate(ctx, "test-uuid").
+			Return(current, nil),
+		ts.mockRepo.EXPECT().UpdateCompany(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, patch *models.CompanyPatch) (int64, error) {
+				assert.Equal(t, "Old Name", *patch.Name)
+				assert.Equal(t, 10, *patch.EmployeesAmount)
+				return 1, nil
+			}),
+		ts.mockRepo.EXPECT().GetCompany(ctx, "test-uuid").
+			Return(old, nil),
+		ts.mockRepo.EXPECT().CreateCompanyVersion(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, v *models.CompanyVersion) error {
+				assert.Equal(t, old, v.Company) // The old state becomes a new version
+				return nil
+			}),
+		ts.mockRepo.EXPECT().CreateOutboxMessage(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, m *models.OutboxMessage) error {
+				assert.Equal(t, EventCompanyUpdated, m.Headers[kafka.HeaderEventType])
+				data := decodeEventData(t, m)
+				assert.Equal(t, []FieldChange{
+					{Field: "Name", Old: "New Name", New: "Old Name"},
+					{Field: "EmployeesAmount", Old: float64(20), New: float64(10)},
+				}, data.Changes)
+				return nil
+			}),
+		ts.mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, record *models.AuditRecord) error {
+				assert.Equal(t, models.AuditActionReverted, record.Action)
+				return nil
+			}),
+	)
+
+	company, err := ts.svc.RevertCompany(ctx, "test-uuid", 1)
+	require.NoError(t, err)
+	assert.Equal(t, old, company)
+}
+
+func TestService_RevertCompany_VersionNotFound(t *testing.T) {
+	ts := newTestService(t)
+	defer ts.Finish()
+	ts.expectTx()
+
+	ts.mockRepo.EXPECT().GetCompanyVersion(ctx, "test-uuid", 5).
+		Return(nil, nil)
+
+	_, err := ts.svc.RevertCompany(ctx, "test-uuid", 5)
+	assert.ErrorIs(t, err, models.ErrVersionNotFound)
+}
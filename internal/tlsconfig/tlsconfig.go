@@ -0,0 +1,202 @@
+// Package tlsconfig builds the TLS configuration of the HTTP server with certificates reloaded on change.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Modes of client certificate verification.
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional" // certificates are verified if given
+	ClientAuthRequire  = "require"
+)
+
+type Config struct {
+	CertFile       string
+	KeyFile        string
+	MinVersion     string   // 1.2 | 1.3
+	CipherSuites   []string // names of crypto/tls, Go defaults if empty; TLS 1.3 suites aren't configurable
+	ClientCAFile   string   // CA bundle verifying client certificates
+	ClientAuth     string   // none | optional | require
+	ReloadInterval time.Duration
+}
+
+// Reloader serves the certificate and the client CA bundle loaded from files
+// and reloads them when the files change.
+type Reloader struct {
+	log  *zap.SugaredLogger
+	cfg  Config
+	base *tls.Config
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+// NewReloader validates the configuration and loads the files.
+func NewReloader(log *zap.SugaredLogger, cfg *Config) (*Reloader, error) {
+	base := &tls.Config{} //nolint:gosec // MinVersion is set below
+
+	switch cfg.MinVersion {
+	case "1.2", "":
+		base.MinVersion = tls.VersionTLS12
+	case "1.3":
+		base.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported TLS version %q", cfg.MinVersion)
+	}
+
+	suites, err := cipherSuites(cfg.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	base.CipherSuites = suites
+
+	switch cfg.ClientAuth {
+	case ClientAuthNone, "":
+		base.ClientAuth = tls.NoClientCert
+	case ClientAuthOptional:
+		base.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		base.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unsupported client auth %q", cfg.ClientAuth)
+	}
+	if base.ClientAuth != tls.NoClientCert && cfg.ClientCAFile == "" {
+		return nil, errors.New("client CA file is required to verify client certificates")
+	}
+
+	r := &Reloader{
+		log:      log,
+		cfg:      *cfg,
+		base:     base,
+		modTimes: make(map[string]time.Time),
+	}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// TLSConfig returns the server configuration, each handshake gets the currently loaded files.
+func (r *Reloader) TLSConfig() *tls.Config {
+	cfg := r.base.Clone()
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		cfg := r.base.Clone()
+		cfg.Certificates = []tls.Certificate{*r.cert}
+		cfg.ClientCAs = r.clientCAs
+		return cfg, nil
+	}
+	return cfg
+}
+
+// Run checks the files for changes every ReloadInterval until ctx is canceled.
+// Files that fail to load are logged, the loaded ones are kept.
+func (r *Reloader) Run(ctx context.Context) {
+	if r.cfg.ReloadInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				r.log.With("error", err).Error("Failed to reload TLS certificates")
+				continue
+			}
+			if reloaded {
+				r.log.Info("TLS certificates reloaded")
+			}
+		}
+	}
+}
+
+// Reload loads the files if any of them was modified since the last load.
+func (r *Reloader) Reload() (reloaded bool, err error) {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+
+	modTimes := make(map[string]time.Time, len(files))
+	changed := false
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		modTimes[file] = info.ModTime()
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("load key pair: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return false, fmt.Errorf("read client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return false, errors.New("no certificates in client CA file")
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.clientCAs, r.modTimes = &cert, clientCAs, modTimes
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+// cipherSuites maps names to IDs of secure cipher suites.
+func cipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	supported := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		supported[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := supported[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,249 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReloader_ReloadsChangedCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca.issue(t, "server-1", certFile, keyFile)
+
+	reloader, err := NewReloader(zap.NewNop().Sugar(), &Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	addr := serve(t, reloader.TLSConfig())
+
+	assert.Equal(t, "server-1", serverCertificate(t, addr, &tls.Config{RootCAs: ca.pool()}).Subject.CommonName)
+
+	reloaded, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded) // Files aren't changed
+
+	ca.issue(t, "server-2", certFile, keyFile)
+	touch(t, certFile, keyFile)
+	reloaded, err = reloader.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+
+	assert.Equal(t, "server-2", serverCertificate(t, addr, &tls.Config{RootCAs: ca.pool()}).Subject.CommonName)
+}
+
+func TestReloader_KeepsCertificateOnInvalidFiles(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca.issue(t, "server", certFile, keyFile)
+
+	reloader, err := NewReloader(zap.NewNop().Sugar(), &Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	touch(t, certFile)
+	_, err = reloader.Reload()
+	require.Error(t, err)
+
+	addr := serve(t, reloader.TLSConfig())
+	assert.Equal(t, "server", serverCertificate(t, addr, &tls.Config{RootCAs: ca.pool()}).Subject.CommonName)
+}
+
+func TestReloader_ClientCertificates(t *testing.T) {
+	ca, otherCA := newTestCA(t), newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca.issue(t, "server", certFile, keyFile)
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, ca.certPEM, 0o600))
+
+	reloader, err := NewReloader(zap.NewNop().Sugar(), &Config{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: caFile,
+		ClientAuth:   ClientAuthRequire,
+	})
+	require.NoError(t, err)
+	addr := serve(t, reloader.TLSConfig())
+
+	clientCert := ca.keyPair(t, "client")
+	conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: ca.pool(), Certificates: []tls.Certificate{clientCert}})
+	require.NoError(t, err)
+	require.NoError(t, conn.Handshake())
+	_ = conn.Close()
+
+	for name, certs := range map[string][]tls.Certificate{
+		"no certificate": nil,
+		"unknown issuer": {otherCA.keyPair(t, "client")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := httpClient(&tls.Config{RootCAs: ca.pool(), Certificates: certs}).Get("https://" + addr)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewReloader_InvalidConfig(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca.issue(t, "server", certFile, keyFile)
+
+	tests := map[string]*Config{
+		"min version":        {CertFile: certFile, KeyFile: keyFile, MinVersion: "1.0"},
+		"insecure suite":     {CertFile: certFile, KeyFile: keyFile, CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+		"client auth":        {CertFile: certFile, KeyFile: keyFile, ClientAuth: "maybe"},
+		"client CA required": {CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthOptional},
+		"missing key":        {CertFile: certFile, KeyFile: filepath.Join(dir, "missing.key")},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewReloader(zap.NewNop().Sugar(), cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewReloader_CipherSuites(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca.issue(t, "server", certFile, keyFile)
+
+	reloader, err := NewReloader(zap.NewNop().Sugar(), &Config{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		MinVersion:   "1.2",
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+	})
+	require.NoError(t, err)
+
+	cfg := reloader.TLSConfig()
+	assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, cfg.CipherSuites)
+}
+
+type testCA struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), key: key}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// issue creates a certificate for 127.0.0.1 signed by the CA and its key, they are written to the files if set.
+func (ca *testCA) issue(t *testing.T, commonName, certFile, keyFile string) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	if certFile != "" {
+		require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+		require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	}
+	return certPEM, keyPEM
+}
+
+func (ca *testCA) keyPair(t *testing.T, commonName string) tls.Certificate {
+	certPEM, keyPEM := ca.issue(t, commonName, "", "")
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	return cert
+}
+
+// touch moves the modification time of the files forward, so they are reloaded even within the timestamp resolution.
+func touch(t *testing.T, files ...string) {
+	for _, file := range files {
+		info, err := os.Stat(file)
+		require.NoError(t, err)
+		modTime := info.ModTime().Add(time.Second)
+		require.NoError(t, os.Chtimes(file, modTime, modTime))
+	}
+}
+
+// serve runs an HTTPS server with the config on a random port.
+func serve(t *testing.T, cfg *tls.Config) string {
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	require.NoError(t, err)
+
+	server := &http.Server{
+		Handler:           http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		ReadHeaderTimeout: time.Second,
+	}
+	go func() { _ = server.Serve(ln) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	return ln.Addr().String()
+}
+
+func serverCertificate(t *testing.T, addr string, cfg *tls.Config) *x509.Certificate {
+	conn, err := tls.Dial("tcp", addr, cfg)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	return conn.ConnectionState().PeerCertificates[0]
+}
+
+func httpClient(cfg *tls.Config) *http.Client {
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}, Timeout: 5 * time.Second}
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "companies_name_pattern_idx";
+DROP INDEX IF EXISTS "companies_updated_at_id_idx";
+DROP INDEX IF EXISTS "companies_created_at_id_idx";
+DROP INDEX IF EXISTS "companies_type_id_idx";
+DROP INDEX IF EXISTS "companies_registered_id_idx";
+DROP INDEX IF EXISTS "companies_employees_amount_id_idx";
+DROP INDEX IF EXISTS "companies_name_id_idx";
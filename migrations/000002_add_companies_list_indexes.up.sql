@@ -0,0 +1,10 @@
+-- Keyset pagination compares (sort_key, id) pairs, so every sortable column gets a composite index.
+CREATE INDEX IF NOT EXISTS "companies_name_id_idx" ON "companies" ("name", "id");
+CREATE INDEX IF NOT EXISTS "companies_employees_amount_id_idx" ON "companies" ("employees_amount", "id");
+CREATE INDEX IF NOT EXISTS "companies_registered_id_idx" ON "companies" ("registered", "id");
+CREATE INDEX IF NOT EXISTS "companies_type_id_idx" ON "companies" ("type", "id");
+CREATE INDEX IF NOT EXISTS "companies_created_at_id_idx" ON "companies" ("created_at", "id");
+CREATE INDEX IF NOT EXISTS "companies_updated_at_id_idx" ON "companies" ((COALESCE("updated_at", '-infinity'::timestamptz)), "id");
+
+-- Name prefix filter (LIKE 'prefix%') independent of the database collation.
+CREATE INDEX IF NOT EXISTS "companies_name_pattern_idx" ON "companies" ("name" varchar_pattern_ops);
@@ -0,0 +1,9 @@
+CREATE TABLE "outbox"
+(
+    "id"              bigserial PRIMARY KEY,
+    "payload"         bytea       NOT NULL,
+    "attempts"        int         NOT NULL DEFAULT 0,
+    "last_error"      text,
+    "next_attempt_at" timestamptz NOT NULL DEFAULT now(),
+    "created_at"      timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,3 @@
+ALTER TABLE "outbox"
+    DROP COLUMN IF EXISTS "headers",
+    DROP COLUMN IF EXISTS "key";
@@ -0,0 +1,3 @@
+ALTER TABLE "outbox"
+    ADD COLUMN "key"     varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN "headers" jsonb        NOT NULL DEFAULT '{}';
@@ -0,0 +1,11 @@
+CREATE TABLE "users"
+(
+    "id"            uuid PRIMARY KEY,
+    "username"      varchar(64)  NOT NULL UNIQUE,
+    "email"         varchar(255) NOT NULL,
+    "password_hash" varchar(255) NOT NULL,
+    "admin"         bool         NOT NULL DEFAULT false,
+    "disabled_at"   timestamptz,
+    "created_at"    timestamptz  NOT NULL DEFAULT now(),
+    "updated_at"    timestamptz
+);
@@ -0,0 +1,22 @@
+-- Refresh tokens are opaque, only their SHA-256 hashes are stored.
+-- Tokens issued by rotation share the family of the token they replace, reuse of a used token revokes the family.
+CREATE TABLE "refresh_tokens"
+(
+    "id"         uuid PRIMARY KEY,
+    "user_id"    uuid        NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+    "family_id"  uuid        NOT NULL,
+    "token_hash" varchar(64) NOT NULL UNIQUE,
+    "expires_at" timestamptz NOT NULL,
+    "used_at"    timestamptz,
+    "revoked_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS "refresh_tokens_family_id_idx" ON "refresh_tokens" ("family_id");
+
+-- Access tokens revoked before they expire, by jti.
+CREATE TABLE "revoked_tokens"
+(
+    "jti"        varchar(64) PRIMARY KEY,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,5 @@
+ALTER TABLE "users"
+    ADD COLUMN "admin" bool NOT NULL DEFAULT false;
+UPDATE "users" SET "admin" = 'admin' = ANY ("roles");
+ALTER TABLE "users"
+    DROP COLUMN "roles";
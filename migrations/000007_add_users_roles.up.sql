@@ -0,0 +1,5 @@
+ALTER TABLE "users"
+    ADD COLUMN "roles" varchar(32)[] NOT NULL DEFAULT '{}';
+UPDATE "users" SET "roles" = '{admin}' WHERE "admin";
+ALTER TABLE "users"
+    DROP COLUMN "admin";
@@ -0,0 +1,15 @@
+-- API keys of service clients. Keys are random, only their SHA-256 hashes are stored,
+-- the prefix identifies a key in listings.
+CREATE TABLE "api_keys"
+(
+    "id"           uuid PRIMARY KEY,
+    "name"         varchar(64)    NOT NULL,
+    "prefix"       varchar(16)    NOT NULL,
+    "key_hash"     varchar(64)    NOT NULL UNIQUE,
+    "scopes"       varchar(32)[]  NOT NULL DEFAULT '{}',
+    "created_by"   varchar(64)    NOT NULL,
+    "expires_at"   timestamptz,
+    "last_used_at" timestamptz,
+    "revoked_at"   timestamptz,
+    "created_at"   timestamptz    NOT NULL DEFAULT now()
+);
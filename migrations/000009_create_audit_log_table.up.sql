@@ -0,0 +1,16 @@
+-- Audit trail of company mutations. Rows are kept after the company is deleted, so there is no foreign key.
+CREATE TABLE "audit_log"
+(
+    "id"         bigserial PRIMARY KEY,
+    "company_id" uuid         NOT NULL,
+    "action"     varchar(16)  NOT NULL,
+    "actor"      varchar(128) NOT NULL,
+    "actor_type" varchar(16)  NOT NULL,
+    "actor_id"   varchar(256),
+    "before"     jsonb,
+    "after"      jsonb,
+    "request_id" varchar(128),
+    "client_ip"  varchar(64),
+    "created_at" timestamptz  NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS "audit_log_company_id_idx" ON "audit_log" ("company_id", "id");
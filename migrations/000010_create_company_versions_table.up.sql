@@ -0,0 +1,20 @@
+-- Every state of a company, numbered from 1. Deleting a company adds a version with its last state marked deleted.
+CREATE TABLE "company_versions"
+(
+    "company_id"       uuid          NOT NULL,
+    "version"          int           NOT NULL,
+    "name"             varchar(15)   NOT NULL,
+    "description"      varchar(3000) NOT NULL DEFAULT '',
+    "employees_amount" int           NOT NULL,
+    "registered"       bool          NOT NULL,
+    "type"             company_type  NOT NULL,
+    "deleted"          bool          NOT NULL DEFAULT false,
+    "created_at"       timestamptz   NOT NULL DEFAULT now(),
+    PRIMARY KEY ("company_id", "version")
+);
+
+-- The current state of existing companies is their first version.
+INSERT INTO "company_versions" ("company_id", "version", "name", "description", "employees_amount", "registered", "type",
+                                "created_at")
+SELECT "id", 1, "name", "description", "employees_amount", "registered", "type", coalesce("updated_at", "created_at")
+FROM "companies";
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "companies_deleted_at_idx";
+DELETE FROM "companies" WHERE "deleted_at" IS NOT NULL;
+DROP INDEX IF EXISTS "companies_name_key";
+ALTER TABLE "companies" ADD CONSTRAINT "companies_name_key" UNIQUE ("name");
+ALTER TABLE "companies" DROP COLUMN IF EXISTS "deleted_at";
@@ -0,0 +1,9 @@
+-- Deleted companies are kept with deleted_at set until they are purged.
+ALTER TABLE "companies" ADD COLUMN "deleted_at" timestamptz;
+
+-- Names are unique among companies that aren't deleted, so a name is free again once its company is deleted.
+ALTER TABLE "companies" DROP CONSTRAINT IF EXISTS "companies_name_key";
+CREATE UNIQUE INDEX IF NOT EXISTS "companies_name_key" ON "companies" ("name") WHERE "deleted_at" IS NULL;
+
+-- The purge job selects the companies deleted the longest time ago.
+CREATE INDEX IF NOT EXISTS "companies_deleted_at_idx" ON "companies" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
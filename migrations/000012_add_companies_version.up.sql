@@ -0,0 +1,7 @@
+-- The version of a company increments on every change, it is the number of the latest row in company_versions.
+ALTER TABLE "companies" ADD COLUMN "version" int NOT NULL DEFAULT 1;
+
+UPDATE "companies" AS "c"
+SET "version" = "v"."version"
+FROM (SELECT "company_id", max("version") AS "version" FROM "company_versions" GROUP BY "company_id") AS "v"
+WHERE "v"."company_id" = "c"."id";
@@ -0,0 +1,15 @@
+-- Requests made with an Idempotency-Key header, keys are unique per principal.
+-- The response is stored when the request completes, status_code is NULL while it is in progress.
+CREATE TABLE "idempotency_keys"
+(
+    "principal"    text         NOT NULL,
+    "key"          varchar(255) NOT NULL,
+    "fingerprint"  varchar(64)  NOT NULL,
+    "status_code"  int,
+    "content_type" text,
+    "body"         bytea,
+    "expires_at"   timestamptz  NOT NULL,
+    "created_at"   timestamptz  NOT NULL DEFAULT now(),
+    PRIMARY KEY ("principal", "key")
+);
+CREATE INDEX IF NOT EXISTS "idempotency_keys_expires_at_idx" ON "idempotency_keys" ("expires_at");
@@ -0,0 +1,3 @@
+-- A request in progress holds its key until locked_until, after that it is considered failed
+-- and a retry takes the key over instead of waiting for expires_at.
+ALTER TABLE "idempotency_keys" ADD COLUMN IF NOT EXISTS "locked_until" timestamptz NOT NULL DEFAULT now();
@@ -0,0 +1 @@
+ALTER TABLE "api_keys" ALTER COLUMN "created_by" TYPE varchar(64) USING left("created_by", 64);
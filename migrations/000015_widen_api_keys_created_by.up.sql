@@ -0,0 +1,2 @@
+-- created_by holds the actor, e.g. "api_key:<name>" or "cert:<CN>", so it is as wide as audit_log.actor.
+ALTER TABLE "api_keys" ALTER COLUMN "created_by" TYPE varchar(128);